@@ -0,0 +1,72 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// MainConfigFileName is the pipelinewise main configuration, read by `import` from the configuration directory
+	// and kept in the state volume for `run_tap`
+	MainConfigFileName string = "config.yml"
+	// LoggingConfigFileName is the python logging configuration passed to pipelinewise through LOGGING_CONF_FILE
+	LoggingConfigFileName string = "logging.conf"
+	// MainConfigMapKey holds a MainConfigSpec as yaml in the operator-level main configuration ConfigMap
+	MainConfigMapKey string = "config.yaml"
+)
+
+// MainConfigSpec defines the pipelinewise main configuration shared by every tap of the job
+type MainConfigSpec struct {
+	// AlertHandlers configures the built-in pipelinewise alerts sent when a tap fails
+	AlertHandlers *AlertHandlersSpec `json:"alertHandlers,omitempty"`
+
+	// Logging replaces the logging configuration shipped with pipelinewise, in python logging configuration file format
+	Logging string `json:"logging,omitempty"`
+}
+
+// AlertHandlersSpec defines the `alert_handlers` section of the pipelinewise main configuration
+type AlertHandlersSpec struct {
+	Slack     *SlackAlertHandlerSpec     `yaml:"slack,omitempty" json:"slack,omitempty"`
+	VictorOps *VictorOpsAlertHandlerSpec `yaml:"victorops,omitempty" json:"victorops,omitempty"`
+}
+
+// SlackAlertHandlerSpec posts pipelinewise alerts to a Slack channel
+type SlackAlertHandlerSpec struct {
+	Token     string           `yaml:"token" json:"token,omitempty"`
+	TokenFrom *ValueFromSource `yaml:"-" json:"token_from,omitempty"`
+	Channel   string           `yaml:"channel" json:"channel"`
+}
+
+// VictorOpsAlertHandlerSpec sends pipelinewise alerts to the VictorOps REST endpoint
+type VictorOpsAlertHandlerSpec struct {
+	BaseURL     string           `yaml:"base_url" json:"base_url,omitempty"`
+	BaseURLFrom *ValueFromSource `yaml:"-" json:"base_url_from,omitempty"`
+	RoutingKey  string           `yaml:"routing_key" json:"routing_key"`
+}
+
+// ConstructMainConfiguration renders the pipelinewise main configuration. Referenced and sensitive values are rendered
+// the same way as in tap and target configurations
+func ConstructMainConfiguration(mainConfig *MainConfigSpec, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) ([]byte, error) {
+	var alertHandlers interface{}
+	if mainConfig.AlertHandlers != nil {
+		var err error
+		alertHandlers, err = renderSensitiveFields(mainConfig.AlertHandlers, []string{}, resolve, encrypt)
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+	return marshalConfiguration(struct {
+		AlertHandlers interface{} `yaml:"alert_handlers,omitempty"`
+	}{alertHandlers})
+}
@@ -0,0 +1,71 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// NotificationConfigMapName is the ConfigMap configuring notifications of every job in its namespace
+// which does not configure notifications itself. The configuration is read from the NotificationConfigMapKey key
+const NotificationConfigMapName string = "pipelinewise-notifications"
+
+// NotificationConfigMapKey holds a NotificationSpec as yaml in the NotificationConfigMapName ConfigMap
+const NotificationConfigMapKey string = "notifications.yaml"
+
+// NotificationReceiverType defines the message format of a notification receiver
+type NotificationReceiverType string
+
+const (
+	// SlackNotificationReceiver posts to a Slack incoming webhook
+	SlackNotificationReceiver NotificationReceiverType = "Slack"
+	// TeamsNotificationReceiver posts to a Microsoft Teams incoming webhook
+	TeamsNotificationReceiver NotificationReceiverType = "Teams"
+	// WebhookNotificationReceiver posts the notification as JSON to any HTTP endpoint
+	WebhookNotificationReceiver NotificationReceiverType = "Webhook"
+)
+
+// NotificationSpec defines where notifications about finished runs are sent. Failed runs and the first successful
+// run after a failure are always notified
+type NotificationSpec struct {
+	// OnSuccess notifies every successful run as well
+	OnSuccess bool `json:"onSuccess,omitempty"`
+
+	// LogTailLines defines how many lines of the failed container output are included, defaults to 20
+	// +kubebuilder:validation:Minimum=0
+	LogTailLines *int32 `json:"logTailLines,omitempty"`
+
+	// Receivers lists the webhooks to notify
+	Receivers []NotificationReceiverSpec `json:"receivers"`
+}
+
+// NotificationReceiverSpec defines a webhook receiving notifications
+type NotificationReceiverSpec struct {
+	// Type defines the message format, either `Slack`, `Teams` or `Webhook`
+	// +kubebuilder:validation:Enum=Slack;Teams;Webhook
+	Type NotificationReceiverType `json:"type"`
+
+	// URLFrom references the webhook URL, which usually carries a token
+	URLFrom ValueFromSource `json:"urlFrom"`
+}
+
+// defaultLogTailLines is the number of output lines included in notifications
+const defaultLogTailLines int32 = 20
+
+// GetLogTailLines returns how many lines of the failed container output are included in notifications
+func GetLogTailLines(notifications *NotificationSpec) int32 {
+	if notifications == nil || notifications.LogTailLines == nil {
+		return defaultLogTailLines
+	}
+	return *notifications.LogTailLines
+}
@@ -0,0 +1,117 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "fmt"
+
+// Pipeline is a tap loaded into a target, the unit pipelinewise runs with `run_tap`
+// +kubebuilder:object:generate=false
+type Pipeline struct {
+	Tap    TapInfo
+	Target TargetInfo
+
+	// Schedule runs the pipeline from a CronJob of its own, pipelines without schedule run on the job schedule
+	Schedule string
+
+	// tapID overrides the tap id of taps loaded into several targets
+	tapID PipelinewiseTapID
+}
+
+// TapID returns the pipelinewise tap id of the pipeline. Taps loaded into several targets are suffixed with the target id,
+// so every copy of the tap keeps a replication state of its own
+func (p Pipeline) TapID() PipelinewiseTapID {
+	if p.tapID != "" {
+		return p.tapID
+	}
+	return p.Tap.ID()
+}
+
+// TargetID returns the pipelinewise target id of the pipeline
+func (p Pipeline) TargetID() PipelinewiseTargetID {
+	return p.Target.ID()
+}
+
+// Tables lists the tables replicated by the pipeline as `<source_schema>.<table_name>`
+func (p Pipeline) Tables() []string {
+	return tapTableNames(p.Tap)
+}
+
+// jobTarget is a configured target of the job together with its schedule
+type jobTarget struct {
+	info     TargetInfo
+	schedule string
+}
+
+// GetPipelines returns the pipelines of the job in run order. Every tap is loaded into every target, a job with a single
+// tap and target runs a single pipeline. Taps keep their own schedule, otherwise the target schedule applies
+func GetPipelines(pwJob *PipelinewiseJob) []Pipeline {
+	targets := []jobTarget{}
+	if len(pwJob.Spec.Targets) == 0 {
+		if targetInfo := getTargetInfo(pwJob); targetInfo != nil {
+			targets = append(targets, jobTarget{info: targetInfo})
+		}
+	}
+	for _, target := range pwJob.Spec.Targets {
+		if targetInfo := targetSpecInfo(target.TargetSpec); targetInfo != nil {
+			targets = append(targets, jobTarget{info: targetInfo, schedule: target.Schedule})
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	taps := []JobTapSpec{{TapSpec: pwJob.Spec.Tap}}
+	if len(pwJob.Spec.Taps) > 0 {
+		taps = pwJob.Spec.Taps
+	}
+	pipelines := []Pipeline{}
+	for _, tap := range taps {
+		tapInfo := tapSpecInfo(tap.TapSpec)
+		if tapInfo == nil {
+			continue
+		}
+		for _, target := range targets {
+			pipeline := Pipeline{Tap: tapInfo, Target: target.info, Schedule: tap.Schedule}
+			if pipeline.Schedule == "" {
+				pipeline.Schedule = target.schedule
+			}
+			if len(pwJob.Spec.Targets) > 1 {
+				pipeline.tapID = PipelinewiseTapID(fmt.Sprintf("%v-%v", tapInfo.ID(), target.info.ID()))
+			}
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+	return pipelines
+}
+
+// ConstructPipelineTapConfiguration renders the tap configuration of the pipeline like ConstructTapConfiguration
+func ConstructPipelineTapConfiguration(pipeline Pipeline, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) ([]byte, error) {
+	dbConn, err := renderSensitiveFields(pipeline.Tap.GetConnection(), []string{}, resolve, encrypt)
+	if err != nil {
+		return []byte{}, err
+	}
+	return constructTap(pipeline.TapID(), pipeline.Tap.Type(), pipeline.TargetID(), dbConn, pipeline.Tap.GetSchemas(), pipeline.Tap.GetSettings())
+}
+
+// ConstructPipelineTargetConfiguration renders the target configuration of the pipeline like ConstructTargetConfiguration
+func ConstructPipelineTargetConfiguration(pipeline Pipeline, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) ([]byte, error) {
+	dbConn, err := renderSensitiveFields(pipeline.Target.GetConnection(), []string{}, resolve, encrypt)
+	if err != nil {
+		return []byte{}, err
+	}
+	return constructTarget(pipeline.TargetID(), pipeline.Target.Type(), dbConn)
+}
@@ -0,0 +1,216 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// vaultTag marks a yaml value as an encrypted string which pipelinewise decrypts with the master password
+	vaultTag string = "!vault"
+	// vaultHeader starts every ansible-vault payload
+	vaultHeader string = "$ANSIBLE_VAULT;"
+)
+
+// ValueFromSource defines a source for a sensitive configuration value, so the value does not need to appear in the PipelinewiseJob
+type ValueFromSource struct {
+	// SecretKeyRef selects a key of a Secret in the PipelinewiseJob namespace
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// SecretValueResolver returns the value rendered for a field referencing a Secret.
+// The field path lists the yaml keys leading to the field inside the connection configuration
+// +kubebuilder:object:generate=false
+type SecretValueResolver func(fieldPath []string, source ValueFromSource) (string, error)
+
+// SensitiveValueEncrypter returns the encrypted form of a sensitive field value, e.g. an ansible-vault string.
+// The field path lists the yaml keys leading to the field inside the connection configuration
+// +kubebuilder:object:generate=false
+type SensitiveValueEncrypter func(fieldPath []string, value string) (string, error)
+
+// valueFromSourceType is the type of every `<Field>From` sibling of a sensitive field
+var valueFromSourceType = reflect.TypeOf(&ValueFromSource{})
+
+// renderSensitiveFields returns a copy of the connection with every referenced sensitive field set to the resolved value.
+// When an encrypter is given, every sensitive value which is not encrypted yet is replaced by its encrypted form
+func renderSensitiveFields(connection interface{}, fieldPath []string, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) (interface{}, error) {
+	connectionVal := reflect.ValueOf(connection)
+	if connectionVal.Kind() == reflect.Ptr {
+		if connectionVal.IsNil() {
+			return connection, nil
+		}
+		connectionVal = connectionVal.Elem()
+	}
+	if connectionVal.Kind() != reflect.Struct {
+		return connection, nil
+	}
+
+	resolved := reflect.New(connectionVal.Type()).Elem()
+	resolved.Set(connectionVal)
+	connectionType := connectionVal.Type()
+	for fieldNth := 0; fieldNth < connectionType.NumField(); fieldNth++ {
+		fieldType := connectionType.Field(fieldNth)
+		fieldVal := resolved.Field(fieldNth)
+
+		if fieldType.Type == valueFromSourceType {
+			valueField, ok := connectionType.FieldByName(strings.TrimSuffix(fieldType.Name, "From"))
+			if !ok {
+				return nil, fmt.Errorf("No field referenced by %v", fieldType.Name)
+			}
+			valuePath := append(append([]string{}, fieldPath...), yamlFieldName(valueField))
+			value := resolved.FieldByIndex(valueField.Index).String()
+			if !fieldVal.IsNil() {
+				if resolve == nil {
+					return nil, fmt.Errorf("No resolver for secret reference of %v", strings.Join(valuePath, "."))
+				}
+				var err error
+				value, err = resolve(valuePath, *fieldVal.Interface().(*ValueFromSource))
+				if err != nil {
+					return nil, err
+				}
+			}
+			if encrypt != nil && value != "" && !isEncryptedValue(value) {
+				var err error
+				value, err = encrypt(valuePath, value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			resolved.FieldByIndex(valueField.Index).SetString(value)
+			continue
+		}
+
+		// Nested configuration, e.g. oauth credentials, may reference secrets as well
+		if fieldType.Type.Kind() == reflect.Struct || (fieldType.Type.Kind() == reflect.Ptr && fieldType.Type.Elem().Kind() == reflect.Struct && !fieldVal.IsNil()) {
+			nested, err := renderSensitiveFields(fieldVal.Interface(), append(append([]string{}, fieldPath...), yamlFieldName(fieldType)), resolve, encrypt)
+			if err != nil {
+				return nil, err
+			}
+			nestedVal := reflect.ValueOf(nested)
+			if fieldType.Type.Kind() == reflect.Ptr {
+				nestedPtr := reflect.New(nestedVal.Type())
+				nestedPtr.Elem().Set(nestedVal)
+				nestedVal = nestedPtr
+			}
+			fieldVal.Set(nestedVal)
+		}
+	}
+
+	return resolved.Interface(), nil
+}
+
+// validateSecretRefs ensures sensitive fields are either set inline or referenced, and references point to a Secret key
+func validateSecretRefs(connection interface{}, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	connectionVal := reflect.ValueOf(connection)
+	if connectionVal.Kind() == reflect.Ptr {
+		if connectionVal.IsNil() {
+			return allErrs
+		}
+		connectionVal = connectionVal.Elem()
+	}
+	if connectionVal.Kind() != reflect.Struct {
+		return allErrs
+	}
+
+	connectionType := connectionVal.Type()
+	for fieldNth := 0; fieldNth < connectionType.NumField(); fieldNth++ {
+		fieldType := connectionType.Field(fieldNth)
+		fieldVal := connectionVal.Field(fieldNth)
+
+		if fieldType.Type == valueFromSourceType {
+			if fieldVal.IsNil() {
+				continue
+			}
+			refPath := fldPath.Child(jsonFieldName(fieldType))
+			if source := fieldVal.Interface().(*ValueFromSource); source.SecretKeyRef == nil || source.SecretKeyRef.Name == "" || source.SecretKeyRef.Key == "" {
+				allErrs = append(allErrs, field.Required(refPath.Child("secretKeyRef"), "secret name and key are required"))
+			}
+			if valueField, ok := connectionType.FieldByName(strings.TrimSuffix(fieldType.Name, "From")); ok && connectionVal.FieldByIndex(valueField.Index).String() != "" {
+				allErrs = append(allErrs, field.Forbidden(refPath, fmt.Sprintf("may not be set together with %v", jsonFieldName(valueField))))
+			}
+			continue
+		}
+
+		if fieldType.Type.Kind() == reflect.Struct || (fieldType.Type.Kind() == reflect.Ptr && fieldType.Type.Elem().Kind() == reflect.Struct) {
+			allErrs = append(allErrs, validateSecretRefs(fieldVal.Interface(), fldPath.Child(jsonFieldName(fieldType)))...)
+		}
+	}
+
+	return allErrs
+}
+
+// isEncryptedValue reports whether the value is an ansible-vault payload, either as printed by `pipelinewise encrypt_string`
+// or without the leading `!vault |`
+func isEncryptedValue(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, vaultTag) || strings.HasPrefix(value, vaultHeader)
+}
+
+// marshalConfiguration renders a tap or target configuration. Encrypted values are emitted as `!vault` tagged
+// literal blocks, which yaml.v2 is not able to produce, hence the rendered document is tagged as yaml.v3 nodes
+func marshalConfiguration(configuration interface{}) ([]byte, error) {
+	rendered, err := yaml.Marshal(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(rendered, &document); err != nil {
+		return nil, err
+	}
+	if !tagVaultValues(&document) {
+		return rendered, nil
+	}
+
+	var tagged bytes.Buffer
+	encoder := yamlv3.NewEncoder(&tagged)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return tagged.Bytes(), nil
+}
+
+// tagVaultValues turns string nodes holding an encrypted value into `!vault` tagged nodes and reports whether any was found
+func tagVaultValues(node *yamlv3.Node) bool {
+	tagged := false
+	for _, child := range node.Content {
+		tagged = tagVaultValues(child) || tagged
+	}
+	if node.Kind != yamlv3.ScalarNode || node.ShortTag() != "!!str" || !isEncryptedValue(node.Value) {
+		return tagged
+	}
+
+	// Drop the `!vault |` prefix and the indentation of values pasted from `pipelinewise encrypt_string`
+	payload := strings.TrimPrefix(strings.TrimSpace(node.Value), vaultTag)
+	payload = strings.TrimPrefix(strings.TrimSpace(payload), "|")
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(payload), "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	node.Tag = vaultTag
+	node.Value = strings.Join(lines, "\n") + "\n"
+	node.Style = yamlv3.LiteralStyle
+	return true
+}
+
+func yamlFieldName(structField reflect.StructField) string {
+	return strings.Split(structField.Tag.Get("yaml"), ",")[0]
+}
+
+func jsonFieldName(structField reflect.StructField) string {
+	return strings.Split(structField.Tag.Get("json"), ",")[0]
+}
@@ -0,0 +1,114 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Secret references", func() {
+	secretRef := func(key string) *ValueFromSource {
+		return &ValueFromSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+				Key:                  key,
+			},
+		}
+	}
+	resolveToPath := func(fieldPath []string, source ValueFromSource) (string, error) {
+		return "resolved:" + strings.Join(fieldPath, ".") + ":" + source.SecretKeyRef.Key, nil
+	}
+
+	It("Should render referenced tap fields with the resolved value", func() {
+		pwJob := &PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Tap: TapSpec{
+					GoogleAnalytics: &GoogleAnalyticsTapSpec{
+						Connection: GoogleAnalyticsTapConnectionSpec{
+							ViewID: "view",
+							OauthCredentials: &GoogleAnalyticsOauthCredentials{
+								ClientID:         "client",
+								ClientSecretFrom: secretRef("client-secret"),
+							},
+						},
+					},
+				},
+				Target: TargetSpec{
+					PostgreSQL: &PostgreSQLTargetSpec{
+						Host:         "postgresql",
+						PasswordFrom: secretRef("password"),
+					},
+				},
+			},
+		}
+
+		tapYaml, err := ConstructTapConfiguration(pwJob, resolveToPath, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		renderedTap := GenericTapSpec{}
+		Expect(yaml.Unmarshal(tapYaml, &renderedTap)).Should(Succeed())
+		Expect(renderedTap.DatabaseConnection).Should(HaveKeyWithValue("oauth_credentials", HaveKeyWithValue("client_secret", "resolved:oauth_credentials.client_secret:client-secret")))
+		Expect(string(tapYaml)).ShouldNot(ContainSubstring("client_secret_from"))
+
+		targetYaml, err := ConstructTargetConfiguration(pwJob, resolveToPath, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(targetYaml)).Should(ContainSubstring("password: resolved:password:password"))
+
+		By("Leaving the PipelinewiseJob untouched")
+		Expect(pwJob.Spec.Tap.GoogleAnalytics.Connection.OauthCredentials.ClientSecret).Should(BeEmpty())
+		Expect(pwJob.Spec.Target.PostgreSQL.Password).Should(BeEmpty())
+	})
+
+	It("Should fail to render references without a resolver", func() {
+		pwJob := &PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Target: TargetSpec{
+					PostgreSQL: &PostgreSQLTargetSpec{
+						PasswordFrom: secretRef("password"),
+					},
+				},
+			},
+		}
+
+		_, err := ConstructTargetConfiguration(pwJob, nil, nil)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("Should render encrypted values as vault tagged nodes", func() {
+		pastedValue := "!vault |\n  $ANSIBLE_VAULT;1.1;AES256\n  3132\n  3334\n"
+		pwJob := &PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Target: TargetSpec{
+					PostgreSQL: &PostgreSQLTargetSpec{
+						Host:     "postgresql",
+						Password: pastedValue,
+					},
+				},
+			},
+		}
+
+		By("Keeping values pasted from pipelinewise encrypt_string")
+		targetYaml, err := ConstructTargetConfiguration(pwJob, nil, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(targetYaml)).Should(ContainSubstring("password: !vault |\n    $ANSIBLE_VAULT;1.1;AES256\n    3132\n    3334\n"))
+
+		By("Tagging values encrypted while rendering")
+		pwJob.Spec.Target.PostgreSQL.Password = "plain"
+		targetYaml, err = ConstructTargetConfiguration(pwJob, nil, func(fieldPath []string, value string) (string, error) {
+			return "$ANSIBLE_VAULT;1.1;AES256\n3536", nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		rendered := struct {
+			DatabaseConnection struct {
+				Password yamlv3.Node `yaml:"password"`
+			} `yaml:"db_conn"`
+		}{}
+		Expect(yamlv3.Unmarshal(targetYaml, &rendered)).Should(Succeed())
+		Expect(rendered.DatabaseConnection.Password.Tag).Should(Equal("!vault"))
+		Expect(rendered.DatabaseConnection.Password.Value).Should(Equal("$ANSIBLE_VAULT;1.1;AES256\n3536\n"))
+		Expect(string(targetYaml)).Should(ContainSubstring("host: postgresql"))
+	})
+})
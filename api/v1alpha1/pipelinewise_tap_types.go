@@ -1,10 +1,13 @@
 package v1alpha1
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
 // PipelinewiseTapID defines tap id
@@ -93,6 +96,7 @@ type TapInfo interface {
 	Type() PipelinewiseTapType
 	GetSchemas() interface{}
 	GetConnection() interface{}
+	GetSettings() TapSettingsSpec
 }
 
 // GenericTapSpec defines generic Pipelinewise Tap configuration
@@ -106,13 +110,72 @@ type GenericTapSpec struct {
 	DatabaseConnection  interface{}          `yaml:"db_conn"`
 	Target              PipelinewiseTargetID `yaml:"target"`
 	Schemas             interface{}          `yaml:"schemas"`
+	TapSettingsSpec     `yaml:",inline"`
+}
+
+// TapSettingsSpec defines Pipelinewise tap-level load settings shared by every tap. [Read more](https://transferwise.github.io/pipelinewise/project/taps_and_targets.html)
+type TapSettingsSpec struct {
+	BatchSizeRows          *int  `yaml:"batch_size_rows,omitempty" json:"batch_size_rows,omitempty"`
+	BatchWaitLimitSeconds  *int  `yaml:"batch_wait_limit_seconds,omitempty" json:"batch_wait_limit_seconds,omitempty"`
+	StreamBufferSize       *int  `yaml:"stream_buffer_size,omitempty" json:"stream_buffer_size,omitempty"`
+	HardDelete             *bool `yaml:"hard_delete,omitempty" json:"hard_delete,omitempty"`
+	AddMetadataColumns     *bool `yaml:"add_metadata_columns,omitempty" json:"add_metadata_columns,omitempty"`
+	DataFlatteningMaxLevel *int  `yaml:"data_flattening_max_level,omitempty" json:"data_flattening_max_level,omitempty"`
+	FlushAllStreams        *bool `yaml:"flush_all_streams,omitempty" json:"flush_all_streams,omitempty"`
+	Parallelism            *int  `yaml:"parallelism,omitempty" json:"parallelism,omitempty"`
+	SplitLargeFiles        *bool `yaml:"split_large_files,omitempty" json:"split_large_files,omitempty"`
+	SplitFileChunkSizeMB   *int  `yaml:"split_file_chunk_size_mb,omitempty" json:"split_file_chunk_size_mb,omitempty"`
+	SplitFileMaxChunks     *int  `yaml:"split_file_max_chunks,omitempty" json:"split_file_max_chunks,omitempty"`
+	ValidateRecords        *bool `yaml:"validate_records,omitempty" json:"validate_records,omitempty"`
+}
+
+// GetSettings implement TapInfo interface to return tap-level load settings
+func (ts *TapSettingsSpec) GetSettings() TapSettingsSpec {
+	return *ts
 }
 
 // TapTableSpec defines Generic Tap Table configuration
 type TapTableSpec struct {
-	TableName         string `yaml:"table_name" json:"table_name"`
-	ReplicationMethod string `yaml:"replication_method" json:"replication_method"`
-	ReplicationKey    string `yaml:"replication_key,omitempty" json:"replication_key,omitempty"`
+	TableName         string               `yaml:"table_name" json:"table_name"`
+	ReplicationMethod string               `yaml:"replication_method" json:"replication_method"`
+	ReplicationKey    string               `yaml:"replication_key,omitempty" json:"replication_key,omitempty"`
+	Transformations   []TransformationSpec `yaml:"transformations,omitempty" json:"transformations,omitempty"`
+}
+
+// TransformationSpec defines a column transformation applied before data reaches the target. [Read more](https://transferwise.github.io/pipelinewise/user_guide/transformations.html)
+type TransformationSpec struct {
+	Column string `yaml:"column" json:"column"`
+	// Type is one of SET-NULL, HASH, HASH-SKIP-FIRST-n, MASK-DATE, MASK-NUMBER or MASK-HIDDEN
+	// +kubebuilder:validation:Pattern=`^(SET-NULL|HASH|HASH-SKIP-FIRST-[1-9][0-9]*|MASK-DATE|MASK-NUMBER|MASK-HIDDEN)$`
+	Type string `yaml:"type" json:"type"`
+	// When applies the transformation only to records matching every condition
+	When []TransformationConditionSpec `yaml:"when,omitempty" json:"when,omitempty"`
+}
+
+// TransformationConditionSpec defines a condition on a column value. Exactly one of equals or regex_match is set
+type TransformationConditionSpec struct {
+	Column string `yaml:"column" json:"column"`
+	// Equals matches the column value exactly, it could be a string, number, boolean or null
+	Equals     *apiextensionsv1.JSON `yaml:"-" json:"equals,omitempty"`
+	RegexMatch string                `yaml:"regex_match,omitempty" json:"regex_match,omitempty"`
+}
+
+// MarshalYAML renders the condition with equals keeping its JSON type
+func (tc TransformationConditionSpec) MarshalYAML() (interface{}, error) {
+	condition := yaml.MapSlice{
+		{Key: "column", Value: tc.Column},
+	}
+	if tc.Equals != nil {
+		var equals interface{}
+		if err := json.Unmarshal(tc.Equals.Raw, &equals); err != nil {
+			return nil, err
+		}
+		condition = append(condition, yaml.MapItem{Key: "equals", Value: equals})
+	}
+	if tc.RegexMatch != "" {
+		condition = append(condition, yaml.MapItem{Key: "regex_match", Value: tc.RegexMatch})
+	}
+	return condition, nil
 }
 
 // TapSchemaSpec defines Generic Tap schema configuration
@@ -124,22 +187,22 @@ type TapSchemaSpec struct {
 
 // MySQLTapConnectionSpec defines MySQL Tap connection configuration
 type MySQLTapConnectionSpec struct {
-	Host            string   `yaml:"host" json:"host"`
-	Port            int      `yaml:"port" json:"port"`
-	User            string   `yaml:"user" json:"user"`
-	Password        string   `yaml:"password" json:"password"`
-	DBName          string   `yaml:"dbname" json:"dbname"`
-	FilterDatabases string   `yaml:"filter_dbs,omitempty" json:"filter_dbs,omitempty"`
-	ExportBatchRows int      `yaml:"export_batch_rows,omitempty" json:"export_batch_rows,omitempty"`
-	SessionSQLs     []string `yaml:"session_sqls,omitempty" json:"session_sqls,omitempty"`
+	Host            string           `yaml:"host" json:"host"`
+	Port            int              `yaml:"port" json:"port"`
+	User            string           `yaml:"user" json:"user"`
+	Password        string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom    *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	DBName          string           `yaml:"dbname" json:"dbname"`
+	FilterDatabases string           `yaml:"filter_dbs,omitempty" json:"filter_dbs,omitempty"`
+	ExportBatchRows int              `yaml:"export_batch_rows,omitempty" json:"export_batch_rows,omitempty"`
+	SessionSQLs     []string         `yaml:"session_sqls,omitempty" json:"session_sqls,omitempty"`
 }
 
 // MySQLTapSpec defines Tap configuration for MySQL. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/mysql.html)
 type MySQLTapSpec struct {
-	Schemas          []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
-	Connection       MySQLTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
-	BatchSizeRows    int                    `yaml:"batch_size_rows" json:"batch_size_rows"`
-	StreamBufferSize int                    `yaml:"stream_buffer_size" json:"stream_buffer_size"`
+	Schemas         []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
+	Connection      MySQLTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID return MySQL connector ID
@@ -169,24 +232,24 @@ func (ts *MySQLTapSpec) GetConnection() interface{} {
 
 // PostgreSQLTapConnectionSpec defines Postgres tap connection configuration
 type PostgreSQLTapConnectionSpec struct {
-	Host                    string `yaml:"host" json:"host"`
-	Port                    int    `yaml:"port" json:"port"`
-	User                    string `yaml:"user" json:"user"`
-	Password                string `yaml:"password" json:"password"`
-	DBName                  string `yaml:"dbname" json:"dbname"`
-	FilterSchemas           string `yaml:"filter_schemas,omitempty" json:"filter_schemas,omitempty"`
-	MaxRunSeconds           int    `yaml:"max_run_seconds,omitempty" json:"max_run_seconds,omitempty"`
-	LogicalPollTotalSeconds int    `yaml:"logical_poll_total_seconds,omitempty" json:"logical_poll_total_seconds,omitempty"`
-	BreakAtEndLSN           bool   `yaml:"break_at_end_lsn,omitempty" json:"break_at_end_lsn,omitempty"`
-	SSL                     bool   `yaml:"ssl,omitempty" json:"ssl,omitempty"`
+	Host                    string           `yaml:"host" json:"host"`
+	Port                    int              `yaml:"port" json:"port"`
+	User                    string           `yaml:"user" json:"user"`
+	Password                string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom            *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	DBName                  string           `yaml:"dbname" json:"dbname"`
+	FilterSchemas           string           `yaml:"filter_schemas,omitempty" json:"filter_schemas,omitempty"`
+	MaxRunSeconds           int              `yaml:"max_run_seconds,omitempty" json:"max_run_seconds,omitempty"`
+	LogicalPollTotalSeconds int              `yaml:"logical_poll_total_seconds,omitempty" json:"logical_poll_total_seconds,omitempty"`
+	BreakAtEndLSN           bool             `yaml:"break_at_end_lsn,omitempty" json:"break_at_end_lsn,omitempty"`
+	SSL                     bool             `yaml:"ssl,omitempty" json:"ssl,omitempty"`
 }
 
 // PostgreSQLTapSpec defines Tap configuration for PostgreSQL. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/postgres.html)
 type PostgreSQLTapSpec struct {
-	Schemas          []TapSchemaSpec             `yaml:"schemas" json:"schemas"`
-	Connection       PostgreSQLTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
-	BatchSizeRows    int                         `yaml:"batch_size_rows" json:"batch_size_rows"`
-	StreamBufferSize int                         `yaml:"stream_buffer_size" json:"stream_buffer_size"`
+	Schemas         []TapSchemaSpec             `yaml:"schemas" json:"schemas"`
+	Connection      PostgreSQLTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -216,20 +279,20 @@ func (ts *PostgreSQLTapSpec) GetConnection() interface{} {
 
 // OracleTapConnectionSpec defines Oracle tap connection configuration
 type OracleTapConnectionSpec struct {
-	SID           string `yaml:"sid" json:"sid"`
-	Host          string `yaml:"host" json:"host"`
-	Port          int    `yaml:"port" json:"port"`
-	User          string `yaml:"user" json:"user"`
-	Password      string `yaml:"password" json:"password"`
-	FilterSchemas string `yaml:"filter_schemas,omitempty" json:"filter_schemas,omitempty"`
+	SID           string           `yaml:"sid" json:"sid"`
+	Host          string           `yaml:"host" json:"host"`
+	Port          int              `yaml:"port" json:"port"`
+	User          string           `yaml:"user" json:"user"`
+	Password      string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom  *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	FilterSchemas string           `yaml:"filter_schemas,omitempty" json:"filter_schemas,omitempty"`
 }
 
 // OracleTapSpec defines Tap configuration for Oracle. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/oracle.html)
 type OracleTapSpec struct {
-	Schemas          []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
-	Connection       OracleTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
-	BatchSizeRows    int                     `yaml:"batch_size_rows" json:"batch_size_rows"`
-	StreamBufferSize int                     `yaml:"stream_buffer_size" json:"stream_buffer_size"`
+	Schemas         []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
+	Connection      OracleTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -281,8 +344,9 @@ type KafkaTapConnectionSpec struct {
 
 // KafkaTapSpec defines Tap configuration for Kafka. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/kafka.html)
 type KafkaTapSpec struct {
-	Schemas    []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
-	Connection KafkaTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
+	Connection      KafkaTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -320,8 +384,9 @@ type S3CSVTableMappingSpec struct {
 
 // S3CSVTapTableSpec defines S3 CSV Tap Table configuration
 type S3CSVTapTableSpec struct {
-	TableName string                `yaml:"table_name" json:"table_name"`
-	Mapping   S3CSVTableMappingSpec `yaml:"s3_csv_mapping" json:"s3_csv_mapping"`
+	TableName       string                `yaml:"table_name" json:"table_name"`
+	Mapping         S3CSVTableMappingSpec `yaml:"s3_csv_mapping" json:"s3_csv_mapping"`
+	Transformations []TransformationSpec  `yaml:"transformations,omitempty" json:"transformations,omitempty"`
 }
 
 // S3CSVTapSchemaSpec defines S3 CSV Tap schema configuration
@@ -333,22 +398,24 @@ type S3CSVTapSchemaSpec struct {
 
 // S3CSVTapConnectionSpec defines S3 CSV Tap connection specification
 type S3CSVTapConnectionSpec struct {
-	AWSProfile         string `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
-	AWSAccessKeyID     string `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
-	AWSSecretAccessKey string `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
-	AWSSessionToken    string `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
-	AWSEndpointURI     string `yaml:"aws_endpoint_uri,omitempty" json:"aws_endpoint_uri,omitempty"`
-	Bucket             string `yaml:"bucket" json:"bucket"`
-	StartDate          string `yaml:"start_date" json:"start_date"`
+	AWSProfile             string           `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
+	AWSAccessKeyID         string           `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
+	AWSAccessKeyIDFrom     *ValueFromSource `yaml:"-" json:"aws_access_key_id_from,omitempty"`
+	AWSSecretAccessKey     string           `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
+	AWSSecretAccessKeyFrom *ValueFromSource `yaml:"-" json:"aws_secret_access_key_from,omitempty"`
+	AWSSessionToken        string           `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
+	AWSSessionTokenFrom    *ValueFromSource `yaml:"-" json:"aws_session_token_from,omitempty"`
+	AWSEndpointURI         string           `yaml:"aws_endpoint_uri,omitempty" json:"aws_endpoint_uri,omitempty"`
+	Bucket                 string           `yaml:"bucket" json:"bucket"`
+	StartDate              string           `yaml:"start_date" json:"start_date"`
 }
 
 // S3CSVTapSpec defines Tap configuration for S3 CSV. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/s3_csv.html)
 type S3CSVTapSpec struct {
 	Schemas             []S3CSVTapSchemaSpec   `yaml:"schemas" json:"schemas"`
 	Connection          S3CSVTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
-	BatchSizeRows       *int                   `yaml:"batch_size_rows,omitempty" json:"batch_size_rows,omitempty"`
-	StreamBufferSize    *int                   `yaml:"stream_buffer_size,omitempty" json:"stream_buffer_size,omitempty"`
 	DefaultTargetSchema string                 `yaml:"default_target_schema,omitempty" json:"default_target_schema,omitempty"`
+	TapSettingsSpec     `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -378,17 +445,19 @@ func (ts *S3CSVTapSpec) GetConnection() interface{} {
 
 // SnowflakeTapConnectionSpec defines Snowflake tap connection
 type SnowflakeTapConnectionSpec struct {
-	Account   string `yaml:"account" json:"account"`
-	DBName    string `yaml:"dbname" json:"dbname"`
-	User      string `yaml:"user" json:"user"`
-	Password  string `yaml:"password" json:"password"`
-	Warehouse string `yaml:"warehouse" json:"warehouse"`
+	Account      string           `yaml:"account" json:"account"`
+	DBName       string           `yaml:"dbname" json:"dbname"`
+	User         string           `yaml:"user" json:"user"`
+	Password     string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	Warehouse    string           `yaml:"warehouse" json:"warehouse"`
 }
 
 // SnowflakeTapSpec defines Tap configuration for Snowflake. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/snowflake.html)
 type SnowflakeTapSpec struct {
-	Schemas    []TapSchemaSpec            `yaml:"schemas" json:"schemas"`
-	Connection SnowflakeTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec            `yaml:"schemas" json:"schemas"`
+	Connection      SnowflakeTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -418,20 +487,22 @@ func (ts *SnowflakeTapSpec) GetConnection() interface{} {
 
 // MongoDBTapConnectionSpec defines MongoDB Tap connection
 type MongoDBTapConnectionSpec struct {
-	Host           string `yaml:"host" json:"host"`
-	Port           int    `yaml:"port" json:"port"`
-	User           string `yaml:"user" json:"user"`
-	Password       string `yaml:"password" json:"password"`
-	AuthDatabase   string `yaml:"auth_database" json:"auth_database"`
-	DBName         string `yaml:"dbname" json:"dbname"`
-	ReplicaSet     string `yaml:"replica_set,omitempty" json:"replica_set,omitempty"`
-	WriteBatchRows *int   `yaml:"write_batch_rows,omitempty" json:"write_batch_rows,omitempty"`
+	Host           string           `yaml:"host" json:"host"`
+	Port           int              `yaml:"port" json:"port"`
+	User           string           `yaml:"user" json:"user"`
+	Password       string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom   *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	AuthDatabase   string           `yaml:"auth_database" json:"auth_database"`
+	DBName         string           `yaml:"dbname" json:"dbname"`
+	ReplicaSet     string           `yaml:"replica_set,omitempty" json:"replica_set,omitempty"`
+	WriteBatchRows *int             `yaml:"write_batch_rows,omitempty" json:"write_batch_rows,omitempty"`
 }
 
 // MongoDBTapSpec defines Tap configuration for MongoDB. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/mongodb.html)
 type MongoDBTapSpec struct {
-	Schemas    []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
-	Connection MongoDBTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
+	Connection      MongoDBTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -461,17 +532,20 @@ func (ts *MongoDBTapSpec) GetConnection() interface{} {
 
 // SalesforceTapConnectionSpec defines Salesforce Tap connection
 type SalesforceTapConnectionSpec struct {
-	ClientID     string `yaml:"client_id" json:"client_id"`
-	ClientSecret string `yaml:"client_secret" json:"client_secret"`
-	RefreshToken string `yaml:"refresh_token" json:"refresh_token"`
-	StartDate    string `yaml:"start_date" json:"start_date"`
-	APIType      string `yaml:"api_type" json:"api_type"`
+	ClientID         string           `yaml:"client_id" json:"client_id"`
+	ClientSecret     string           `yaml:"client_secret" json:"client_secret,omitempty"`
+	ClientSecretFrom *ValueFromSource `yaml:"-" json:"client_secret_from,omitempty"`
+	RefreshToken     string           `yaml:"refresh_token" json:"refresh_token,omitempty"`
+	RefreshTokenFrom *ValueFromSource `yaml:"-" json:"refresh_token_from,omitempty"`
+	StartDate        string           `yaml:"start_date" json:"start_date"`
+	APIType          string           `yaml:"api_type" json:"api_type"`
 }
 
 // SalesforceTapSpec defines Tap configuration for Salesforce. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/salesforce.html)
 type SalesforceTapSpec struct {
-	Schemas    []TapSchemaSpec             `yaml:"schemas" json:"schemas"`
-	Connection SalesforceTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec             `yaml:"schemas" json:"schemas"`
+	Connection      SalesforceTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -501,18 +575,20 @@ func (ts *SalesforceTapSpec) GetConnection() interface{} {
 
 // ZendeskTapConnectionSpec defines Zendesk Tap connection
 type ZendeskTapConnectionSpec struct {
-	AccessToken string `yaml:"access_token" json:"access_token"`
-	Subdomain   string `yaml:"subdomain" json:"subdomain"`
-	StartDate   string `yaml:"start_date" json:"start_date"`
-	RateLimit   *int   `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
-	MaxWorkers  *int   `yaml:"max_workers,omitempty" json:"max_workers,omitempty"`
-	BatchSize   *int   `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
+	AccessToken     string           `yaml:"access_token" json:"access_token,omitempty"`
+	AccessTokenFrom *ValueFromSource `yaml:"-" json:"access_token_from,omitempty"`
+	Subdomain       string           `yaml:"subdomain" json:"subdomain"`
+	StartDate       string           `yaml:"start_date" json:"start_date"`
+	RateLimit       *int             `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	MaxWorkers      *int             `yaml:"max_workers,omitempty" json:"max_workers,omitempty"`
+	BatchSize       *int             `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
 }
 
 // ZendeskTapSpec defines Tap configuration for Zendesk. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/zendesk.html)
 type ZendeskTapSpec struct {
-	Schemas    []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
-	Connection ZendeskTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
+	Connection      ZendeskTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -542,21 +618,26 @@ func (ts *ZendeskTapSpec) GetConnection() interface{} {
 
 // JiraTapConnectionSpec defines Jira Tap connection
 type JiraTapConnectionSpec struct {
-	BaseURL           string `yaml:"base_url" json:"base_url"`
-	Username          string `yaml:"username,omitempty" json:"username,omitempty"`
-	Password          string `yaml:"password,omitempty" json:"password,omitempty"`
-	OauthClientSecret string `yaml:"oauth_client_secret,omitempty" json:"oauth_client_secret,omitempty"`
-	OauthClientID     string `yaml:"oauth_client_id,omitempty" json:"oauth_client_id,omitempty"`
-	AccessToken       string `yaml:"access_token,omitempty" json:"access_token,omitempty"`
-	CloudID           string `yaml:"cloud_id,omitempty" json:"cloud_id,omitempty"`
-	RefreshToken      string `yaml:"refresh_token,omitempty" json:"refresh_token,omitempty"`
-	StartData         string `yaml:"start_date" json:"start_date"`
+	BaseURL               string           `yaml:"base_url" json:"base_url"`
+	Username              string           `yaml:"username,omitempty" json:"username,omitempty"`
+	Password              string           `yaml:"password,omitempty" json:"password,omitempty"`
+	PasswordFrom          *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	OauthClientSecret     string           `yaml:"oauth_client_secret,omitempty" json:"oauth_client_secret,omitempty"`
+	OauthClientSecretFrom *ValueFromSource `yaml:"-" json:"oauth_client_secret_from,omitempty"`
+	OauthClientID         string           `yaml:"oauth_client_id,omitempty" json:"oauth_client_id,omitempty"`
+	AccessToken           string           `yaml:"access_token,omitempty" json:"access_token,omitempty"`
+	AccessTokenFrom       *ValueFromSource `yaml:"-" json:"access_token_from,omitempty"`
+	CloudID               string           `yaml:"cloud_id,omitempty" json:"cloud_id,omitempty"`
+	RefreshToken          string           `yaml:"refresh_token,omitempty" json:"refresh_token,omitempty"`
+	RefreshTokenFrom      *ValueFromSource `yaml:"-" json:"refresh_token_from,omitempty"`
+	StartData             string           `yaml:"start_date" json:"start_date"`
 }
 
 // JiraTapSpec defines Tap configuration for Jira. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/jira.html)
 type JiraTapSpec struct {
-	Schemas    []TapSchemaSpec       `yaml:"schemas" json:"schemas"`
-	Connection JiraTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec       `yaml:"schemas" json:"schemas"`
+	Connection      JiraTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -586,19 +667,21 @@ func (ts *JiraTapSpec) GetConnection() interface{} {
 
 // ZuoraTapConnectionSpec defines Zuora Tap connection
 type ZuoraTapConnectionSpec struct {
-	Username  string `yaml:"username,omitempty" json:"username,omitempty"`
-	Password  string `yaml:"password,omitempty" json:"password,omitempty"`
-	PartnerID string `yaml:"partner_id,omitempty" json:"partner_id,omitempty"`
-	APIType   string `yaml:"api_type" json:"api_type"`
-	Sandbox   bool   `yaml:"sandbox,omitempty" json:"sandbox,omitempty"`
-	European  bool   `yaml:"european,omitempty" json:"european,omitempty"`
-	StartData string `yaml:"start_date" json:"start_date"`
+	Username     string           `yaml:"username,omitempty" json:"username,omitempty"`
+	Password     string           `yaml:"password,omitempty" json:"password,omitempty"`
+	PasswordFrom *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	PartnerID    string           `yaml:"partner_id,omitempty" json:"partner_id,omitempty"`
+	APIType      string           `yaml:"api_type" json:"api_type"`
+	Sandbox      bool             `yaml:"sandbox,omitempty" json:"sandbox,omitempty"`
+	European     bool             `yaml:"european,omitempty" json:"european,omitempty"`
+	StartData    string           `yaml:"start_date" json:"start_date"`
 }
 
 // ZuoraTapSpec defines Tap configuration for Zuora. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/zuora.html)
 type ZuoraTapSpec struct {
-	Schemas    []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
-	Connection ZuoraTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
+	Connection      ZuoraTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -632,10 +715,13 @@ func (ts *ZuoraTapSpec) GetConnection() interface{} {
 
 // GoogleAnalyticsOauthCredentials defines Google Analytics Oauth Credentials
 type GoogleAnalyticsOauthCredentials struct {
-	ClientID     string `yaml:"client_id" json:"client_id"`
-	ClientSecret string `yaml:"client_secret" json:"client_secret"`
-	AccessToken  string `yaml:"access_token" json:"access_token"`
-	RefreshToken string `yaml:"refresh_token" json:"refresh_token"`
+	ClientID         string           `yaml:"client_id" json:"client_id"`
+	ClientSecret     string           `yaml:"client_secret" json:"client_secret,omitempty"`
+	ClientSecretFrom *ValueFromSource `yaml:"-" json:"client_secret_from,omitempty"`
+	AccessToken      string           `yaml:"access_token" json:"access_token,omitempty"`
+	AccessTokenFrom  *ValueFromSource `yaml:"-" json:"access_token_from,omitempty"`
+	RefreshToken     string           `yaml:"refresh_token" json:"refresh_token,omitempty"`
+	RefreshTokenFrom *ValueFromSource `yaml:"-" json:"refresh_token_from,omitempty"`
 }
 
 // GoogleAnalyticsTapConnectionSpec defines Google Analytics Tap connection
@@ -648,8 +734,9 @@ type GoogleAnalyticsTapConnectionSpec struct {
 
 // GoogleAnalyticsTapSpec defines Tap configuration for Google Analytics. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/google_analytics.html)
 type GoogleAnalyticsTapSpec struct {
-	Schemas    []TapSchemaSpec                  `yaml:"schemas" json:"schemas"`
-	Connection GoogleAnalyticsTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec                  `yaml:"schemas" json:"schemas"`
+	Connection      GoogleAnalyticsTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -679,14 +766,16 @@ func (ts *GoogleAnalyticsTapSpec) GetConnection() interface{} {
 
 // GithubTapConnectionSpec defines Github Tap connection
 type GithubTapConnectionSpec struct {
-	AccessToken string `yaml:"access_token" json:"access_token"`
-	Repository  string `yaml:"repository" json:"repository"`
+	AccessToken     string           `yaml:"access_token" json:"access_token,omitempty"`
+	AccessTokenFrom *ValueFromSource `yaml:"-" json:"access_token_from,omitempty"`
+	Repository      string           `yaml:"repository" json:"repository"`
 }
 
 // GithubTapSpec defines Tap configuration for Github. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/github.html)
 type GithubTapSpec struct {
-	Schemas    []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
-	Connection GithubTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
+	Connection      GithubTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -716,15 +805,17 @@ func (ts *GithubTapSpec) GetConnection() interface{} {
 
 // ShopifyTapConnectionSpec defines Shopify Tap connection
 type ShopifyTapConnectionSpec struct {
-	Shop      string `yaml:"shop" json:"shop"`
-	APIKey    string `yaml:"api_key" json:"api_key"`
-	StartDate string `yaml:"start_date" json:"start_date"`
+	Shop       string           `yaml:"shop" json:"shop"`
+	APIKey     string           `yaml:"api_key" json:"api_key,omitempty"`
+	APIKeyFrom *ValueFromSource `yaml:"-" json:"api_key_from,omitempty"`
+	StartDate  string           `yaml:"start_date" json:"start_date"`
 }
 
 // ShopifyTapSpec defines Tap configuration for Shopify. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/shopify.html)
 type ShopifyTapSpec struct {
-	Schemas    []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
-	Connection ShopifyTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec          `yaml:"schemas" json:"schemas"`
+	Connection      ShopifyTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -754,20 +845,22 @@ func (ts *ShopifyTapSpec) GetConnection() interface{} {
 
 // SlackTapConnectionSpec defines Slack Tap connection
 type SlackTapConnectionSpec struct {
-	Token              string   `yaml:"token" json:"token"`
-	StartDate          string   `yaml:"start_date" json:"start_date"`
-	Channels           []string `yaml:"channels,omitempty" json:"channels,omitempty"`
-	ExcludeArchived    string   `yaml:"exclude_archived,omitempty" json:"exclude_archived,omitempty"`
-	PrivateChannels    string   `yaml:"private_channels,omitempty" json:"private_channels,omitempty"`
-	JoinPublicChannels string   `yaml:"join_public_channels,omitempty" json:"join_public_channels,omitempty"`
-	DateWindowSize     string   `yaml:"date_window_size,omitempty" json:"date_window_size,omitempty"`
-	LookbackWindow     int      `yaml:"lookback_window,omitempty" json:"lookback_window,omitempty"`
+	Token              string           `yaml:"token" json:"token,omitempty"`
+	TokenFrom          *ValueFromSource `yaml:"-" json:"token_from,omitempty"`
+	StartDate          string           `yaml:"start_date" json:"start_date"`
+	Channels           []string         `yaml:"channels,omitempty" json:"channels,omitempty"`
+	ExcludeArchived    string           `yaml:"exclude_archived,omitempty" json:"exclude_archived,omitempty"`
+	PrivateChannels    string           `yaml:"private_channels,omitempty" json:"private_channels,omitempty"`
+	JoinPublicChannels string           `yaml:"join_public_channels,omitempty" json:"join_public_channels,omitempty"`
+	DateWindowSize     string           `yaml:"date_window_size,omitempty" json:"date_window_size,omitempty"`
+	LookbackWindow     int              `yaml:"lookback_window,omitempty" json:"lookback_window,omitempty"`
 }
 
 // SlackTapSpec defines Tap configuration for Slack. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/slack.html)
 type SlackTapSpec struct {
-	Schemas    []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
-	Connection SlackTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec        `yaml:"schemas" json:"schemas"`
+	Connection      SlackTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -797,20 +890,22 @@ func (ts *SlackTapSpec) GetConnection() interface{} {
 
 // MixpanelTapConnectionSpec defines Mixpanel Tap connection
 type MixpanelTapConnectionSpec struct {
-	APISecret         string   `yaml:"api_secret" json:"api_secret"`
-	StartDate         string   `yaml:"start_date" json:"start_date"`
-	DateWindowSize    int      `yaml:"date_window_size,omitempty" json:"date_window_size,omitempty"`
-	AttributionWindow int      `yaml:"attribution_window,omitempty" json:"attribution_window,omitempty"`
-	ProjectTimezone   string   `yaml:"project_timezone,omitempty" json:"project_timezone,omitempty"`
-	UserAgent         string   `yaml:"user_agent,omitempty" json:"user_agent,omitempty"`
-	DenestProperties  string   `yaml:"denest_properties,omitempty" json:"denest_properties,omitempty"`
-	ExportEvents      []string `yaml:"export_events,omitempty" json:"export_events,omitempty"`
+	APISecret         string           `yaml:"api_secret" json:"api_secret,omitempty"`
+	APISecretFrom     *ValueFromSource `yaml:"-" json:"api_secret_from,omitempty"`
+	StartDate         string           `yaml:"start_date" json:"start_date"`
+	DateWindowSize    int              `yaml:"date_window_size,omitempty" json:"date_window_size,omitempty"`
+	AttributionWindow int              `yaml:"attribution_window,omitempty" json:"attribution_window,omitempty"`
+	ProjectTimezone   string           `yaml:"project_timezone,omitempty" json:"project_timezone,omitempty"`
+	UserAgent         string           `yaml:"user_agent,omitempty" json:"user_agent,omitempty"`
+	DenestProperties  string           `yaml:"denest_properties,omitempty" json:"denest_properties,omitempty"`
+	ExportEvents      []string         `yaml:"export_events,omitempty" json:"export_events,omitempty"`
 }
 
 // MixpanelTapSpec defines Tap configuration for Mixpanel. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/mixpanel.html)
 type MixpanelTapSpec struct {
-	Schemas    []TapSchemaSpec           `yaml:"schemas" json:"schemas"`
-	Connection MixpanelTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec           `yaml:"schemas" json:"schemas"`
+	Connection      MixpanelTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -840,16 +935,18 @@ func (ts *MixpanelTapSpec) GetConnection() interface{} {
 
 // TwilioTapConnectionSpec defines Twilio Tap connection
 type TwilioTapConnectionSpec struct {
-	AccountSID string `yaml:"account_sid" json:"account_sid"`
-	AuthToken  string `yaml:"auth_token" json:"auth_token"`
-	StartDate  string `yaml:"start_date" json:"start_date"`
-	UserAgent  string `yaml:"user_agent,omitempty" json:"user_agent,omitempty"`
+	AccountSID    string           `yaml:"account_sid" json:"account_sid"`
+	AuthToken     string           `yaml:"auth_token" json:"auth_token,omitempty"`
+	AuthTokenFrom *ValueFromSource `yaml:"-" json:"auth_token_from,omitempty"`
+	StartDate     string           `yaml:"start_date" json:"start_date"`
+	UserAgent     string           `yaml:"user_agent,omitempty" json:"user_agent,omitempty"`
 }
 
 // TwilioTapSpec defines Tap configuration for Twilio. [Read more](https://transferwise.github.io/pipelinewise/connectors/taps/twilio.html)
 type TwilioTapSpec struct {
-	Schemas    []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
-	Connection TwilioTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	Schemas         []TapSchemaSpec         `yaml:"schemas" json:"schemas"`
+	Connection      TwilioTapConnectionSpec `yaml:"db_conn" json:"db_conn"`
+	TapSettingsSpec `yaml:",inline" json:",inline"`
 }
 
 // ConnectorID implement TapInfo interface to return Pipelinewise Tap ID
@@ -878,7 +975,29 @@ func (ts *TwilioTapSpec) GetConnection() interface{} {
 }
 
 func getTapInfo(pwJob *PipelinewiseJob) TapInfo {
-	pwVal := reflect.ValueOf(pwJob.Spec.Tap)
+	return tapSpecInfo(pwJob.Spec.Tap)
+}
+
+// getTapInfos returns the configured taps of the job, in the listed order for jobs with several taps
+func getTapInfos(pwJob *PipelinewiseJob) []TapInfo {
+	tapInfos := []TapInfo{}
+	if len(pwJob.Spec.Taps) == 0 {
+		if tapInfo := getTapInfo(pwJob); tapInfo != nil {
+			tapInfos = append(tapInfos, tapInfo)
+		}
+		return tapInfos
+	}
+	for _, tap := range pwJob.Spec.Taps {
+		if tapInfo := tapSpecInfo(tap.TapSpec); tapInfo != nil {
+			tapInfos = append(tapInfos, tapInfo)
+		}
+	}
+	return tapInfos
+}
+
+// tapSpecInfo returns the configured tap of the spec
+func tapSpecInfo(tap TapSpec) TapInfo {
+	pwVal := reflect.ValueOf(tap)
 	for fieldNth := 0; fieldNth < pwVal.NumField(); fieldNth++ {
 		field := pwVal.Field(fieldNth)
 		if !field.IsNil() {
@@ -888,19 +1007,25 @@ func getTapInfo(pwJob *PipelinewiseJob) TapInfo {
 	return nil
 }
 
-// ConstructTapConfiguration parse and return a tap yaml configuration string
-func ConstructTapConfiguration(pwJob *PipelinewiseJob) ([]byte, error) {
+// ConstructTapConfiguration parse and return a tap yaml configuration string.
+// Fields referencing a Secret are rendered with the value returned by the resolver, sensitive values are encrypted
+// when an encrypter is given
+func ConstructTapConfiguration(pwJob *PipelinewiseJob, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) ([]byte, error) {
 	tapInfo := getTapInfo(pwJob)
 	targetID := GetTargetID(pwJob)
 
 	if tapInfo != nil {
-		return constructTap(tapInfo.ID(), tapInfo.Type(), targetID, tapInfo.GetConnection(), tapInfo.GetSchemas())
+		dbConn, err := renderSensitiveFields(tapInfo.GetConnection(), []string{}, resolve, encrypt)
+		if err != nil {
+			return []byte{}, err
+		}
+		return constructTap(tapInfo.ID(), tapInfo.Type(), targetID, dbConn, tapInfo.GetSchemas(), tapInfo.GetSettings())
 	}
 
 	return []byte{}, fmt.Errorf("No Valid Tap configured")
 }
 
-func constructTap(tapID PipelinewiseTapID, tapType PipelinewiseTapType, targetID PipelinewiseTargetID, dbConn interface{}, schemas interface{}) ([]byte, error) {
+func constructTap(tapID PipelinewiseTapID, tapType PipelinewiseTapType, targetID PipelinewiseTargetID, dbConn interface{}, schemas interface{}, settings TapSettingsSpec) ([]byte, error) {
 	tapConfiguration := GenericTapSpec{
 		DatabaseConnection: dbConn,
 		ID:                 tapID,
@@ -908,24 +1033,30 @@ func constructTap(tapID PipelinewiseTapID, tapType PipelinewiseTapType, targetID
 		Type:               tapType,
 		Target:             targetID,
 		Schemas:            schemas,
+		TapSettingsSpec:    settings,
 	}
-	return yaml.Marshal(tapConfiguration)
+	return marshalConfiguration(tapConfiguration)
 }
 
-// GetTapID calculate pipelinewise tap id
+// GetTapID calculate pipelinewise tap id. Jobs with several taps return the comma separated ids of their taps
 func GetTapID(pwJob *PipelinewiseJob) PipelinewiseTapID {
-	tapInfo := getTapInfo(pwJob)
-	if tapInfo != nil {
-		return tapInfo.ID()
+	tapIDs := []string{}
+	for _, tapInfo := range getTapInfos(pwJob) {
+		tapIDs = append(tapIDs, string(tapInfo.ID()))
 	}
-	return ""
+	return PipelinewiseTapID(strings.Join(tapIDs, ","))
 }
 
-// GetTapConnectorID calculate pipelinewise connector id
+// GetTapConnectorID calculate pipelinewise connector id. Jobs with several taps return the comma separated distinct
+// connector ids of their taps
 func GetTapConnectorID(pwJob *PipelinewiseJob) string {
-	tapInfo := getTapInfo(pwJob)
-	if tapInfo != nil {
-		return tapInfo.ConnectorID()
+	connectorIDs := []string{}
+	seen := map[string]bool{}
+	for _, tapInfo := range getTapInfos(pwJob) {
+		if !seen[tapInfo.ConnectorID()] {
+			connectorIDs = append(connectorIDs, tapInfo.ConnectorID())
+			seen[tapInfo.ConnectorID()] = true
+		}
 	}
-	return ""
+	return strings.Join(connectorIDs, ",")
 }
@@ -0,0 +1,198 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("Tap configuration", func() {
+	intValue := func(v int) *int { return &v }
+	boolValue := func(v bool) *bool { return &v }
+
+	settings := TapSettingsSpec{
+		BatchSizeRows:          intValue(20000),
+		BatchWaitLimitSeconds:  intValue(3600),
+		StreamBufferSize:       intValue(0),
+		HardDelete:             boolValue(false),
+		AddMetadataColumns:     boolValue(true),
+		DataFlatteningMaxLevel: intValue(2),
+		FlushAllStreams:        boolValue(true),
+		Parallelism:            intValue(4),
+		SplitLargeFiles:        boolValue(true),
+		SplitFileChunkSizeMB:   intValue(500),
+		SplitFileMaxChunks:     intValue(10),
+		ValidateRecords:        boolValue(false),
+	}
+	expectedSettings := map[string]interface{}{
+		"batch_size_rows":           20000,
+		"batch_wait_limit_seconds":  3600,
+		"stream_buffer_size":        0,
+		"hard_delete":               false,
+		"add_metadata_columns":      true,
+		"data_flattening_max_level": 2,
+		"flush_all_streams":         true,
+		"parallelism":               4,
+		"split_large_files":         true,
+		"split_file_chunk_size_mb":  500,
+		"split_file_max_chunks":     10,
+		"validate_records":          false,
+	}
+
+	It("Should render tap-level settings of every tap", func() {
+		tapSpecType := reflect.TypeOf(TapSpec{})
+		for fieldNth := 0; fieldNth < tapSpecType.NumField(); fieldNth++ {
+			tapField := tapSpecType.Field(fieldNth)
+			By("Rendering " + tapField.Name)
+
+			tap := reflect.New(tapField.Type.Elem())
+			tap.Elem().FieldByName("TapSettingsSpec").Set(reflect.ValueOf(settings))
+			pwJob := &PipelinewiseJob{
+				Spec: PipelinewiseJobSpec{
+					Target: TargetSpec{
+						PostgreSQL: &PostgreSQLTargetSpec{},
+					},
+				},
+			}
+			reflect.ValueOf(&pwJob.Spec.Tap).Elem().Field(fieldNth).Set(tap)
+
+			tapYaml, err := ConstructTapConfiguration(pwJob, nil, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			rendered := map[string]interface{}{}
+			Expect(yaml.Unmarshal(tapYaml, &rendered)).Should(Succeed())
+			for key, value := range expectedSettings {
+				Expect(rendered).Should(HaveKeyWithValue(key, value), "%v should render %v", tapField.Name, key)
+			}
+		}
+	})
+
+	It("Should omit unset tap-level settings", func() {
+		pwJob := &PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Tap: TapSpec{
+					MySQL: &MySQLTapSpec{},
+				},
+				Target: TargetSpec{
+					PostgreSQL: &PostgreSQLTargetSpec{},
+				},
+			},
+		}
+
+		tapYaml, err := ConstructTapConfiguration(pwJob, nil, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		rendered := map[string]interface{}{}
+		Expect(yaml.Unmarshal(tapYaml, &rendered)).Should(Succeed())
+		for key := range expectedSettings {
+			Expect(rendered).ShouldNot(HaveKey(key))
+		}
+	})
+
+	It("Should render column transformations", func() {
+		transformations := []TransformationSpec{
+			{
+				Column: "email",
+				Type:   "HASH-SKIP-FIRST-2",
+			},
+			{
+				Column: "birth_date",
+				Type:   "MASK-DATE",
+				When: []TransformationConditionSpec{
+					{
+						Column: "is_test_user",
+						Equals: &apiextensionsv1.JSON{Raw: []byte("false")},
+					},
+					{
+						Column:     "country",
+						RegexMatch: "^(DE|NL)$",
+					},
+				},
+			},
+		}
+		expectedTransformations := `transformations:
+  - column: email
+    type: HASH-SKIP-FIRST-2
+  - column: birth_date
+    type: MASK-DATE
+    when:
+    - column: is_test_user
+      equals: false
+    - column: country
+      regex_match: ^(DE|NL)$
+`
+		targetSpec := TargetSpec{
+			PostgreSQL: &PostgreSQLTargetSpec{},
+		}
+
+		By("Rendering generic tap tables")
+		tapYaml, err := ConstructTapConfiguration(&PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Tap: TapSpec{
+					MySQL: &MySQLTapSpec{
+						Schemas: []TapSchemaSpec{
+							{
+								Tables: []TapTableSpec{
+									{
+										TableName:         "users",
+										ReplicationMethod: FullTableReplication,
+										Transformations:   transformations,
+									},
+								},
+							},
+						},
+					},
+				},
+				Target: targetSpec,
+			},
+		}, nil, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(tapYaml)).Should(ContainSubstring(indent(expectedTransformations, "  ")))
+
+		By("Rendering S3 CSV tap tables")
+		tapYaml, err = ConstructTapConfiguration(&PipelinewiseJob{
+			Spec: PipelinewiseJobSpec{
+				Tap: TapSpec{
+					S3CSV: &S3CSVTapSpec{
+						Schemas: []S3CSVTapSchemaSpec{
+							{
+								Tables: []S3CSVTapTableSpec{
+									{
+										TableName:       "users",
+										Transformations: transformations,
+									},
+								},
+							},
+						},
+					},
+				},
+				Target: targetSpec,
+			},
+		}, nil, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(tapYaml)).Should(ContainSubstring(indent(expectedTransformations, "  ")))
+	})
+})
+
+// indent prefixes every line of a yaml snippet to match its nesting in the rendered document
+func indent(snippet, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSuffix(snippet, "\n"), "\n", "\n"+prefix) + "\n"
+}
@@ -3,8 +3,7 @@ package v1alpha1
 import (
 	"fmt"
 	"reflect"
-
-	"gopkg.in/yaml.v2"
+	"strings"
 )
 
 // PipelinewiseTargetID defines pipelinewise target id
@@ -59,11 +58,12 @@ type GenericTargetSpec struct {
 
 // PostgreSQLTargetSpec defines PostgreSQL Target configuration. [Read more](https://transferwise.github.io/pipelinewise/connectors/targets/postgres.html)
 type PostgreSQLTargetSpec struct {
-	Host         string `yaml:"host" json:"host"`
-	Port         int    `yaml:"port" json:"port"`
-	User         string `yaml:"user" json:"user"`
-	Password     string `yaml:"password" json:"password"`
-	DatabaseName string `yaml:"dbname" json:"dbname"`
+	Host         string           `yaml:"host" json:"host"`
+	Port         int              `yaml:"port" json:"port"`
+	User         string           `yaml:"user" json:"user"`
+	Password     string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	DatabaseName string           `yaml:"dbname" json:"dbname"`
 }
 
 // ConnectorID implements TargetInfo interface to return connection id
@@ -88,20 +88,24 @@ func (ts *PostgreSQLTargetSpec) GetConnection() interface{} {
 
 // RedshiftTargetSpec defines Redshift Target configuration. [Read more](https://transferwise.github.io/pipelinewise/connectors/targets/redshift.html)
 type RedshiftTargetSpec struct {
-	Host                   string `yaml:"host" json:"host"`
-	Port                   int    `yaml:"port" json:"port"`
-	User                   string `yaml:"user" json:"user"`
-	Password               string `yaml:"password" json:"password"`
-	DatabaseName           string `yaml:"dbname" json:"dbname"`
-	AWSProfile             string `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
-	AWSAccessKeyID         string `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
-	AWSAccessSecretKey     string `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
-	AWSSessionToken        string `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
-	AWSRedshiftCopyRoleARN string `yaml:"aws_redshift_copy_role_arn,omitempty" json:"aws_redshift_copy_role_arn,omitempty"`
-	S3Bucket               string `yaml:"s3_bucket" json:"s3_bucket"`
-	S3KeyPrefix            string `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
-	S3ACL                  string `yaml:"s3_acl,omitempty" json:"s3_acl,omitempty"`
-	CopyOptions            string `yaml:"copy_options" json:"copy_options"`
+	Host                   string           `yaml:"host" json:"host"`
+	Port                   int              `yaml:"port" json:"port"`
+	User                   string           `yaml:"user" json:"user"`
+	Password               string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom           *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	DatabaseName           string           `yaml:"dbname" json:"dbname"`
+	AWSProfile             string           `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
+	AWSAccessKeyID         string           `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
+	AWSAccessKeyIDFrom     *ValueFromSource `yaml:"-" json:"aws_access_key_id_from,omitempty"`
+	AWSAccessSecretKey     string           `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
+	AWSAccessSecretKeyFrom *ValueFromSource `yaml:"-" json:"aws_secret_access_key_from,omitempty"`
+	AWSSessionToken        string           `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
+	AWSSessionTokenFrom    *ValueFromSource `yaml:"-" json:"aws_session_token_from,omitempty"`
+	AWSRedshiftCopyRoleARN string           `yaml:"aws_redshift_copy_role_arn,omitempty" json:"aws_redshift_copy_role_arn,omitempty"`
+	S3Bucket               string           `yaml:"s3_bucket" json:"s3_bucket"`
+	S3KeyPrefix            string           `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
+	S3ACL                  string           `yaml:"s3_acl,omitempty" json:"s3_acl,omitempty"`
+	CopyOptions            string           `yaml:"copy_options" json:"copy_options"`
 }
 
 // ConnectorID implements TargetInfo interface to return connection id
@@ -126,22 +130,27 @@ func (ts *RedshiftTargetSpec) GetConnection() interface{} {
 
 // SnowflakeTargetSpec defines Snowflake Target configuration. [Read more](https://transferwise.github.io/pipelinewise/connectors/targets/snowflake.html)
 type SnowflakeTargetSpec struct {
-	Account                       string `yaml:"account" json:"account"`
-	DatabaseName                  string `yaml:"dbname" json:"dbname"`
-	User                          string `yaml:"user" json:"user"`
-	Password                      string `yaml:"password" json:"password"`
-	Warehouse                     string `yaml:"warehouse" json:"warehouse"`
-	AWSProfile                    string `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
-	AWSAccessKeyID                string `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
-	AWSSecretAccessKey            string `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
-	AWSSessionToken               string `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
-	AWSEndpointURL                string `yaml:"aws_session_url" json:"aws_session_url"`
-	S3Bucket                      string `yaml:"s3_bucket" json:"s3_bucket"`
-	S3KeyPrefix                   string `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
-	S3ACL                         string `yaml:"s3_acl,omitempty" json:"s3_acl,omitempty"`
-	Stage                         string `yaml:"schema" json:"schema"`
-	FileFormat                    string `yaml:"file_format" json:"file_format"`
-	ClientSideEncryptionMasterKey string `yaml:"client_side_encryption_master_key,omitempty" json:"client_side_encryption_master_key,omitempty"`
+	Account                           string           `yaml:"account" json:"account"`
+	DatabaseName                      string           `yaml:"dbname" json:"dbname"`
+	User                              string           `yaml:"user" json:"user"`
+	Password                          string           `yaml:"password" json:"password,omitempty"`
+	PasswordFrom                      *ValueFromSource `yaml:"-" json:"password_from,omitempty"`
+	Warehouse                         string           `yaml:"warehouse" json:"warehouse"`
+	AWSProfile                        string           `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
+	AWSAccessKeyID                    string           `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
+	AWSAccessKeyIDFrom                *ValueFromSource `yaml:"-" json:"aws_access_key_id_from,omitempty"`
+	AWSSecretAccessKey                string           `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
+	AWSSecretAccessKeyFrom            *ValueFromSource `yaml:"-" json:"aws_secret_access_key_from,omitempty"`
+	AWSSessionToken                   string           `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
+	AWSSessionTokenFrom               *ValueFromSource `yaml:"-" json:"aws_session_token_from,omitempty"`
+	AWSEndpointURL                    string           `yaml:"aws_session_url" json:"aws_session_url"`
+	S3Bucket                          string           `yaml:"s3_bucket" json:"s3_bucket"`
+	S3KeyPrefix                       string           `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
+	S3ACL                             string           `yaml:"s3_acl,omitempty" json:"s3_acl,omitempty"`
+	Stage                             string           `yaml:"schema" json:"schema"`
+	FileFormat                        string           `yaml:"file_format" json:"file_format"`
+	ClientSideEncryptionMasterKey     string           `yaml:"client_side_encryption_master_key,omitempty" json:"client_side_encryption_master_key,omitempty"`
+	ClientSideEncryptionMasterKeyFrom *ValueFromSource `yaml:"-" json:"client_side_encryption_master_key_from,omitempty"`
 }
 
 // ConnectorID implements TargetInfo interface to return connection id
@@ -166,16 +175,20 @@ func (ts *SnowflakeTargetSpec) GetConnection() interface{} {
 
 // S3CSVTargetSpec defines S3 CSV Target configuration. [Read more](https://transferwise.github.io/pipelinewise/connectors/targets/s3_csv.html)
 type S3CSVTargetSpec struct {
-	AWSProfile         string `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
-	AWSAccessKeyID     string `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
-	AWSSecretAccessKey string `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
-	AWSSessionToken    string `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
-	S3Bucket           string `yaml:"s3_bucket" json:"s3_bucket"`
-	S3KeyPrefix        string `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
-	Delimiter          string `yaml:"delimiter,omitempty" json:"delimiter,omitempty"`
-	QuoteChar          string `yaml:"quotechar,omitempty" json:"quotechar,omitempty"`
-	EncryptionType     string `yaml:"encryption_type,omitempty" json:"encryption_type,omitempty"`
-	EncryptionKey      string `yaml:"encryption_key,omitempty" json:"encryption_key,omitempty"`
+	AWSProfile             string           `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty"`
+	AWSAccessKeyID         string           `yaml:"aws_access_key_id,omitempty" json:"aws_access_key_id,omitempty"`
+	AWSAccessKeyIDFrom     *ValueFromSource `yaml:"-" json:"aws_access_key_id_from,omitempty"`
+	AWSSecretAccessKey     string           `yaml:"aws_secret_access_key,omitempty" json:"aws_secret_access_key,omitempty"`
+	AWSSecretAccessKeyFrom *ValueFromSource `yaml:"-" json:"aws_secret_access_key_from,omitempty"`
+	AWSSessionToken        string           `yaml:"aws_session_token,omitempty" json:"aws_session_token,omitempty"`
+	AWSSessionTokenFrom    *ValueFromSource `yaml:"-" json:"aws_session_token_from,omitempty"`
+	S3Bucket               string           `yaml:"s3_bucket" json:"s3_bucket"`
+	S3KeyPrefix            string           `yaml:"s3_key_prefix,omitempty" json:"s3_key_prefix,omitempty"`
+	Delimiter              string           `yaml:"delimiter,omitempty" json:"delimiter,omitempty"`
+	QuoteChar              string           `yaml:"quotechar,omitempty" json:"quotechar,omitempty"`
+	EncryptionType         string           `yaml:"encryption_type,omitempty" json:"encryption_type,omitempty"`
+	EncryptionKey          string           `yaml:"encryption_key,omitempty" json:"encryption_key,omitempty"`
+	EncryptionKeyFrom      *ValueFromSource `yaml:"-" json:"encryption_key_from,omitempty"`
 }
 
 // ConnectorID implements TargetInfo interface to return connection id
@@ -199,7 +212,29 @@ func (ts *S3CSVTargetSpec) GetConnection() interface{} {
 }
 
 func getTargetInfo(pwJob *PipelinewiseJob) TargetInfo {
-	pwVal := reflect.ValueOf(pwJob.Spec.Target)
+	return targetSpecInfo(pwJob.Spec.Target)
+}
+
+// getTargetInfos returns the configured targets of the job, in the listed order for jobs with several targets
+func getTargetInfos(pwJob *PipelinewiseJob) []TargetInfo {
+	targetInfos := []TargetInfo{}
+	if len(pwJob.Spec.Targets) == 0 {
+		if targetInfo := getTargetInfo(pwJob); targetInfo != nil {
+			targetInfos = append(targetInfos, targetInfo)
+		}
+		return targetInfos
+	}
+	for _, target := range pwJob.Spec.Targets {
+		if targetInfo := targetSpecInfo(target.TargetSpec); targetInfo != nil {
+			targetInfos = append(targetInfos, targetInfo)
+		}
+	}
+	return targetInfos
+}
+
+// targetSpecInfo returns the configured target of the spec
+func targetSpecInfo(target TargetSpec) TargetInfo {
+	pwVal := reflect.ValueOf(target)
 	for fieldNth := 0; fieldNth < pwVal.NumField(); fieldNth++ {
 		field := pwVal.Field(fieldNth)
 		if !field.IsNil() {
@@ -209,30 +244,41 @@ func getTargetInfo(pwJob *PipelinewiseJob) TargetInfo {
 	return nil
 }
 
-// GetTargetConnectorID defines pipelinewise target connector id
+// GetTargetConnectorID defines pipelinewise target connector id. Jobs with several targets return the comma separated
+// distinct connector ids of their targets
 func GetTargetConnectorID(pwJob *PipelinewiseJob) string {
-	targetInfo := getTargetInfo(pwJob)
-	if targetInfo != nil {
-		return targetInfo.ConnectorID()
+	connectorIDs := []string{}
+	seen := map[string]bool{}
+	for _, targetInfo := range getTargetInfos(pwJob) {
+		if !seen[targetInfo.ConnectorID()] {
+			connectorIDs = append(connectorIDs, targetInfo.ConnectorID())
+			seen[targetInfo.ConnectorID()] = true
+		}
 	}
-	return ""
+	return strings.Join(connectorIDs, ",")
 }
 
-// GetTargetID calculate pipelinewise target id
+// GetTargetID calculate pipelinewise target id. Jobs with several targets return the comma separated ids of their targets
 func GetTargetID(pipelinewiseJob *PipelinewiseJob) PipelinewiseTargetID {
-	targetInfo := getTargetInfo(pipelinewiseJob)
-	if targetInfo != nil {
-		return targetInfo.ID()
+	targetIDs := []string{}
+	for _, targetInfo := range getTargetInfos(pipelinewiseJob) {
+		targetIDs = append(targetIDs, string(targetInfo.ID()))
 	}
-	return ""
+	return PipelinewiseTargetID(strings.Join(targetIDs, ","))
 }
 
-// ConstructTargetConfiguration parse and return a target yaml configuration string
-func ConstructTargetConfiguration(pwJob *PipelinewiseJob) ([]byte, error) {
+// ConstructTargetConfiguration parse and return a target yaml configuration string.
+// Fields referencing a Secret are rendered with the value returned by the resolver, sensitive values are encrypted
+// when an encrypter is given
+func ConstructTargetConfiguration(pwJob *PipelinewiseJob, resolve SecretValueResolver, encrypt SensitiveValueEncrypter) ([]byte, error) {
 	targetInfo := getTargetInfo(pwJob)
 
 	if targetInfo != nil {
-		return constructTarget(targetInfo.ID(), targetInfo.Type(), targetInfo.GetConnection())
+		dbConn, err := renderSensitiveFields(targetInfo.GetConnection(), []string{}, resolve, encrypt)
+		if err != nil {
+			return []byte{}, err
+		}
+		return constructTarget(targetInfo.ID(), targetInfo.Type(), dbConn)
 	}
 
 	return []byte{}, fmt.Errorf("No Valid Tap configured")
@@ -245,5 +291,5 @@ func constructTarget(pwID PipelinewiseTargetID, pwType PipelinewiseTargetType, d
 		Name:               string(pwID),
 		Type:               pwType,
 	}
-	return yaml.Marshal(targetConfiguration)
+	return marshalConfiguration(targetConfiguration)
 }
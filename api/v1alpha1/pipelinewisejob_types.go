@@ -17,6 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -61,8 +65,17 @@ type PipelinewiseJobSpec struct {
 	// Image override executor image. If not supplied it will be calculated based on tap and target id
 	Image *string `json:"image,omitempty"`
 
-	// Schedule defines cron expression of the job
-	Schedule string `json:"schedule"`
+	// Mode defines how the job is executed, either `Scheduled` on the cron schedule or `Continuous` in a loop. Defaults to `Scheduled`
+	// +kubebuilder:validation:Enum=Scheduled;Continuous
+	// +optional
+	Mode ExecutionMode `json:"mode,omitempty"`
+
+	// Schedule defines cron expression of the job, required in `Scheduled` mode
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Continuous configures the loop running the tap in `Continuous` mode
+	Continuous *ContinuousSpec `json:"continuous,omitempty"`
 
 	// Suspend flags the job to suspend subsequent executions
 	Suspend *bool `json:"suspend,omitempty"`
@@ -73,12 +86,289 @@ type PipelinewiseJobSpec struct {
 	// FailedJobsHistoryLimit define how many failed finished job to retain
 	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
 
-	// All Pipelinewise job spec. Specify your simplified tap and target configuration
-	Tap    TapSpec    `json:"tap"`
-	Target TargetSpec `json:"target"`
+	// ConcurrencyPolicy defines how to treat a scheduled run while the previous one is still running, either `Allow`, `Forbid` or `Replace`.
+	// Defaults to `Forbid`, so two runs never write the same state file
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +optional
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// StartingDeadlineSeconds defines how late a missed run may still be started
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// BackoffLimit defines how many times a failed run is retried
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// ActiveDeadlineSeconds defines how long a run may take before it is terminated and reported as timed out
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// TTLSecondsAfterFinished defines how long a finished run is kept before it is deleted
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// RunHistoryLimit defines how many finished runs are kept with their statistics in status, defaults to 5
+	// +kubebuilder:validation:Minimum=0
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+
+	// All Pipelinewise job spec. Specify your simplified tap and target configuration, or reference a shared
+	// PipelinewiseTap and PipelinewiseTarget of the job namespace instead
+	Tap    TapSpec    `json:"tap,omitempty"`
+	Target TargetSpec `json:"target,omitempty"`
+
+	// TapRef references a PipelinewiseTap used instead of an inline tap
+	TapRef *corev1.LocalObjectReference `json:"tapRef,omitempty"`
+
+	// TargetRef references a PipelinewiseTarget used instead of an inline target
+	TargetRef *corev1.LocalObjectReference `json:"targetRef,omitempty"`
+
+	// Taps lists several taps loaded into the target of the job instead of a single tap. Every tap is rendered into its
+	// own configuration file. Taps without a schedule of their own run one after another in the listed order on the job schedule
+	Taps []JobTapSpec `json:"taps,omitempty"`
+
+	// Targets lists several targets every tap of the job is loaded into instead of a single target. Every target gets
+	// its own copy of each tap, with a tap id suffixed by the target id and a replication state of its own
+	Targets []JobTargetSpec `json:"targets,omitempty"`
 
 	// Secret defines if the configuration uses [encrypted string](https://transferwise.github.io/pipelinewise/user_guide/encrypting_passwords.html)
 	Secret *SecretSpec `json:"secret,omitempty"`
+
+	// Encrypted lets the operator encrypt every sensitive value with the master password from Secret,
+	// so plaintext values and Secret references end up as encrypted strings in the rendered configuration
+	Encrypted bool `json:"encrypted,omitempty"`
+
+	// ConfigStorage defines where the rendered tap and target configuration is stored, either `Secret` or `ConfigMap`. Defaults to `Secret`
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// +optional
+	ConfigStorage ConfigStorageType `json:"configStorage,omitempty"`
+
+	// PodTemplate overrides the executor pod, e.g. resources, scheduling and security settings
+	PodTemplate *PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// Volume configures the state volume mounted at `/root/.pipelinewise`, which keeps replication bookmarks between runs
+	Volume *VolumeSpec `json:"volume,omitempty"`
+
+	// Resync requests a resync of selected tables, or of the whole tap, with `pipelinewise sync_tables`
+	Resync *ResyncSpec `json:"resync,omitempty"`
+
+	// Notifications configures webhooks notified about failed, recovered and optionally successful runs.
+	// Jobs without notifications use the `pipelinewise-notifications` ConfigMap of their namespace, if present
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
+
+	// MainConfig renders the pipelinewise main configuration, e.g. alert handlers. Jobs without main configuration use
+	// the operator-level ConfigMap, if the operator is configured with one
+	MainConfig *MainConfigSpec `json:"mainConfig,omitempty"`
+
+	// StateEdit requests a reset of the replication state, or changes the bookmarks of single streams
+	StateEdit *StateEditSpec `json:"stateEdit,omitempty"`
+}
+
+// JobTapSpec defines one of the taps of a job with several taps
+type JobTapSpec struct {
+	TapSpec `json:",inline"`
+
+	// TapRef references a PipelinewiseTap used instead of an inline tap
+	TapRef *corev1.LocalObjectReference `json:"tapRef,omitempty"`
+
+	// Schedule runs the tap from a CronJob of its own on this cron expression, instead of together with the other taps on the job schedule
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// JobTargetSpec defines one of the targets of a job loading its taps into several targets
+type JobTargetSpec struct {
+	TargetSpec `json:",inline"`
+
+	// TargetRef references a PipelinewiseTarget used instead of an inline target
+	TargetRef *corev1.LocalObjectReference `json:"targetRef,omitempty"`
+
+	// Schedule loads the taps into the target from a CronJob of its own on this cron expression, instead of together
+	// with the other targets on the job schedule. Taps with a schedule of their own keep it
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// ResyncSpec defines a resync request. Every distinct token runs once, the schedule is paused while the resync runs
+type ResyncSpec struct {
+	// Token identifies the request, change it to resync again
+	Token string `json:"token"`
+
+	// Tables lists the tables to resync as `<source_schema>.<table_name>`. The whole tap is resynced when empty
+	Tables []string `json:"tables,omitempty"`
+}
+
+// StateEditSpec defines a change of the replication state stored in `state.json` on the state volume.
+// Every distinct token runs once, the previous state file is backed up before it is changed
+type StateEditSpec struct {
+	// Token identifies the request, change it to edit the state again
+	Token string `json:"token"`
+
+	// Tap selects the tap whose state is edited by its tap id, required for jobs with several taps or targets
+	Tap string `json:"tap,omitempty"`
+
+	// Reset drops the whole state of the tap before the stream bookmarks are applied
+	Reset bool `json:"reset,omitempty"`
+
+	// Streams lists bookmarks to set or clear
+	Streams []StreamBookmarkSpec `json:"streams,omitempty"`
+}
+
+// StreamBookmarkSpec defines the bookmark of a single stream
+type StreamBookmarkSpec struct {
+	// Stream is the tap stream id as written to the state, e.g. `<source_schema>-<table_name>`
+	Stream string `json:"stream"`
+
+	// Bookmark replaces the bookmark of the stream, e.g. `{"log_file": "mysql-bin.000003", "log_pos": 4}`,
+	// `{"replication_key": "updated_at", "replication_key_value": "2021-01-01"}` or `{"lsn": 108683608}`.
+	// The bookmark is cleared when empty, so the stream is synced from scratch
+	// +kubebuilder:validation:Type=object
+	Bookmark *apiextensionsv1.JSON `json:"bookmark,omitempty"`
+}
+
+// ContinuousSpec defines the loop of a continuously running job
+type ContinuousSpec struct {
+	// PauseSeconds defines how long to wait before the tap is run again, defaults to 60
+	// +kubebuilder:validation:Minimum=0
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+}
+
+// VolumeSpec defines the state volume of the job
+type VolumeSpec struct {
+	// Size of the state volume, defaults to 1Gi. Increasing the size expands the claim when its storage class allows volume expansion
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the state volume, the cluster default storage class is used when empty. Only applied when the claim is created
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes of the state volume, defaults to ReadWriteOnce. Only applied when the claim is created
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// ExistingClaimName mounts an existing claim instead of creating one. The claim is never modified or deleted by the operator
+	ExistingClaimName string `json:"existingClaimName,omitempty"`
+
+	// RetainOnDelete keeps the state volume when the PipelinewiseJob is deleted, so a job recreated with the same name resumes from its state
+	RetainOnDelete bool `json:"retainOnDelete,omitempty"`
+}
+
+// PodTemplateSpec defines overrides merged into the executor pod spawned for every run
+type PodTemplateSpec struct {
+	// Labels are added to the executor pod
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the executor pod
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// NodeSelector constrains the executor pod to nodes with matching labels
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the executor pod
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the executor pod
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName of the executor pod
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ServiceAccountName runs the executor pod with the given service account
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// ImagePullSecrets used to pull the executor image
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// SecurityContext of the executor pod
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// Container overrides merged into both the `import` and the `runner` container
+	Container *ContainerOverrideSpec `json:"container,omitempty"`
+
+	// Import overrides merged into the `import` init container, on top of Container. Jobs with several taps merge them into every import container
+	Import *ContainerOverrideSpec `json:"import,omitempty"`
+
+	// Runner overrides merged into the `runner` container, on top of Container. Jobs with several taps merge them into every runner container
+	Runner *ContainerOverrideSpec `json:"runner,omitempty"`
+}
+
+// ContainerOverrideSpec defines overrides of an executor container
+type ContainerOverrideSpec struct {
+	// Resources are merged per resource name into the container requests and limits
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SecurityContext replaces the container security context
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// Env variables are added to the container, replacing variables with the same name
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// ImagePullPolicy of the container
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// ExecutionMode defines how the job is executed
+type ExecutionMode string
+
+const (
+	// ScheduledExecutionMode runs the job from a CronJob on its schedule
+	ScheduledExecutionMode ExecutionMode = "Scheduled"
+	// ContinuousExecutionMode runs the job non-stop from a single replica Deployment, pausing between runs
+	ContinuousExecutionMode ExecutionMode = "Continuous"
+)
+
+// defaultPauseSeconds is the pause between runs of a continuous job
+const defaultPauseSeconds int32 = 60
+
+// GetExecutionMode returns the configured execution mode, falling back to ScheduledExecutionMode
+func GetExecutionMode(pwJob *PipelinewiseJob) ExecutionMode {
+	if pwJob.Spec.Mode == "" {
+		return ScheduledExecutionMode
+	}
+	return pwJob.Spec.Mode
+}
+
+// GetPauseSeconds returns the pause between runs of a continuous job
+func GetPauseSeconds(pwJob *PipelinewiseJob) int32 {
+	if pwJob.Spec.Continuous == nil || pwJob.Spec.Continuous.PauseSeconds == nil {
+		return defaultPauseSeconds
+	}
+	return *pwJob.Spec.Continuous.PauseSeconds
+}
+
+// defaultRunHistoryLimit is the number of finished runs kept in status
+const defaultRunHistoryLimit int32 = 5
+
+// GetRunHistoryLimit returns how many finished runs are kept in status
+func GetRunHistoryLimit(pwJob *PipelinewiseJob) int32 {
+	if pwJob.Spec.RunHistoryLimit == nil {
+		return defaultRunHistoryLimit
+	}
+	return *pwJob.Spec.RunHistoryLimit
+}
+
+// ConfigStorageType defines the kind of resource holding the rendered configuration
+type ConfigStorageType string
+
+const (
+	// SecretConfigStorage renders the configuration into a Secret, so connection settings are only readable with Secret access
+	SecretConfigStorage ConfigStorageType = "Secret"
+	// ConfigMapConfigStorage renders the configuration into a ConfigMap
+	ConfigMapConfigStorage ConfigStorageType = "ConfigMap"
+)
+
+// GetConfigStorage returns the configured storage of the rendered configuration, falling back to SecretConfigStorage
+func GetConfigStorage(pwJob *PipelinewiseJob) ConfigStorageType {
+	if pwJob.Spec.ConfigStorage == "" {
+		return SecretConfigStorage
+	}
+	return pwJob.Spec.ConfigStorage
+}
+
+// GetConcurrencyPolicy returns the configured concurrency policy of the executor CronJob, falling back to Forbid
+func GetConcurrencyPolicy(pwJob *PipelinewiseJob) batchv1.ConcurrencyPolicy {
+	if pwJob.Spec.ConcurrencyPolicy == "" {
+		return batchv1.ForbidConcurrent
+	}
+	return pwJob.Spec.ConcurrencyPolicy
 }
 
 // SecretSpec defines secret specification for loading master password for [encrypted string](https://transferwise.github.io/pipelinewise/user_guide/encrypting_passwords.html)
@@ -87,14 +377,187 @@ type SecretSpec struct {
 	Key  string `json:"key"`
 }
 
+// RunNowAnnotation triggers a one-off run of the job. Every distinct value runs once, the annotation is removed after the run starts
+const RunNowAnnotation string = "pipelinewise.batch/run-now"
+
+const (
+	// ConditionConfigRendered reports whether tap and target configuration could be rendered
+	ConditionConfigRendered string = "ConfigRendered"
+	// ConditionScheduled reports whether the executor CronJob is in place and not suspended, or the continuous executor is running
+	ConditionScheduled string = "Scheduled"
+	// ConditionLastRunSucceeded reports the outcome of the most recent finished run
+	ConditionLastRunSucceeded string = "LastRunSucceeded"
+	// ConditionDegraded reports whether the operator failed to reconcile the job resources
+	ConditionDegraded string = "Degraded"
+)
+
 // PipelinewiseJobStatus defines the observed state of PipelinewiseJob
 type PipelinewiseJobStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the most recent PipelinewiseJob generation reconciled by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the latest observation of the job. Known types are `ConfigRendered`, `Scheduled`, `LastRunSucceeded` and `Degraded`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastScheduleTime is the last time the executor CronJob scheduled a run
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the last time a run finished successfully
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// Active lists the names of currently running Jobs
+	Active []string `json:"active,omitempty"`
+
+	// LastManualRun records the latest run triggered through the run-now annotation
+	LastManualRun *ManualRunStatus `json:"lastManualRun,omitempty"`
+
+	// LastResync records the progress of the latest resync request
+	LastResync *ResyncStatus `json:"lastResync,omitempty"`
+
+	// LastStateEdit records the progress of the latest state edit request
+	LastStateEdit *StateEditStatus `json:"lastStateEdit,omitempty"`
+
+	// RecentRuns records the statistics of the latest finished runs, most recent first
+	RecentRuns []RunStatistics `json:"recentRuns,omitempty"`
+}
+
+// RunStatistics defines the outcome of a finished run
+type RunStatistics struct {
+	// JobName is the name of the Job of the run
+	JobName string `json:"jobName"`
+
+	// RunType is `scheduled` for runs started by the CronJob, otherwise the type of the one-off run, e.g. `manual` or `resync`
+	RunType string `json:"runType"`
+
+	// Succeeded reports whether the Job of the run finished successfully
+	Succeeded bool `json:"succeeded"`
+
+	// ExitCode of the runner container, of the last runner that ran for jobs with several taps. Unset when the pod of the run was already removed
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// FailedContainer is the name of the container which failed the run
+	FailedContainer string `json:"failedContainer,omitempty"`
+
+	// StartTime is the time the Job of the run started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the Job of the run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// DurationSeconds is the time between start and completion of the Job
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// Tables lists the rows replicated per table, parsed from the tap and target logs of the runners
+	Tables []TableStatistics `json:"tables,omitempty"`
+
+	// NotificationPending is set until the receivers of the job are notified about the run, failed deliveries are retried
+	// +optional
+	NotificationPending bool `json:"notificationPending,omitempty"`
+}
+
+// TableStatistics defines the rows replicated for a single table during a run
+type TableStatistics struct {
+	// Name of the table
+	Name string `json:"name"`
+
+	// Tap is the tap id of the pipeline which replicated the table
+	Tap string `json:"tap,omitempty"`
+
+	// Target is the target id of the pipeline which replicated the table
+	Target string `json:"target,omitempty"`
+
+	// RowsExtracted counts the records emitted by the tap
+	RowsExtracted int64 `json:"rowsExtracted"`
+
+	// RowsLoaded counts the rows inserted or updated by the target
+	RowsLoaded int64 `json:"rowsLoaded"`
+}
+
+// OperationPhase defines the progress of a resync or state edit request
+type OperationPhase string
+
+const (
+	// OperationPending waits for active runs to finish before the operation starts
+	OperationPending OperationPhase = "Pending"
+	// OperationRunning is set while the Job of the operation runs
+	OperationRunning OperationPhase = "Running"
+	// OperationSucceeded is set once the Job of the operation finished successfully
+	OperationSucceeded OperationPhase = "Succeeded"
+	// OperationFailed is set once the Job of the operation failed
+	OperationFailed OperationPhase = "Failed"
+)
+
+// ResyncStatus defines the progress of a resync request
+type ResyncStatus struct {
+	// Token of the resync request
+	Token string `json:"token"`
+
+	// Tables resynced by the request, empty when the whole tap is resynced
+	Tables []string `json:"tables,omitempty"`
+
+	// Phase of the resync, one of `Pending`, `Running`, `Succeeded` or `Failed`
+	Phase OperationPhase `json:"phase"`
+
+	// JobName is the name of the Job created for the resync
+	JobName string `json:"jobName,omitempty"`
+
+	// StartTime is the time the Job was created
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the Job finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// StateEditStatus defines the progress of a state edit request
+type StateEditStatus struct {
+	// Token of the state edit request
+	Token string `json:"token"`
+
+	// Phase of the state edit, one of `Pending`, `Running`, `Succeeded` or `Failed`
+	Phase OperationPhase `json:"phase"`
+
+	// JobName is the name of the Job created for the state edit
+	JobName string `json:"jobName,omitempty"`
+
+	// BackupPath is the location of the previous state file on the state volume
+	BackupPath string `json:"backupPath,omitempty"`
+
+	// StartTime is the time the Job was created
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the Job finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ManualRunStatus defines a run triggered outside of the schedule
+type ManualRunStatus struct {
+	// Token is the run-now annotation value which triggered the run
+	Token string `json:"token"`
+
+	// JobName is the name of the Job created for the run, empty when the run was skipped
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Skipped is set when the token was consumed without a run, as jobs in Continuous mode have no manual runs
+	// +optional
+	Skipped bool `json:"skipped,omitempty"`
+
+	// TriggeredTime is the time the Job was created or the run was skipped
+	TriggeredTime metav1.Time `json:"triggeredTime"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessfulTime`
+// +kubebuilder:printcolumn:name="Succeeded",type=string,JSONPath=`.status.conditions[?(@.type=="LastRunSucceeded")].status`
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // PipelinewiseJob is the Schema for the pipelinewisejobs API
 type PipelinewiseJob struct {
@@ -114,6 +577,54 @@ type PipelinewiseJobList struct {
 	Items           []PipelinewiseJob `json:"items"`
 }
 
+// TapRefs lists the PipelinewiseTaps referenced by the job
+func (r *PipelinewiseJob) TapRefs() []corev1.LocalObjectReference {
+	refs := []corev1.LocalObjectReference{}
+	if r.Spec.TapRef != nil {
+		refs = append(refs, *r.Spec.TapRef)
+	}
+	for _, tap := range r.Spec.Taps {
+		if tap.TapRef != nil {
+			refs = append(refs, *tap.TapRef)
+		}
+	}
+	return refs
+}
+
+// TargetRefs lists the PipelinewiseTargets referenced by the job
+func (r *PipelinewiseJob) TargetRefs() []corev1.LocalObjectReference {
+	refs := []corev1.LocalObjectReference{}
+	if r.Spec.TargetRef != nil {
+		refs = append(refs, *r.Spec.TargetRef)
+	}
+	for _, target := range r.Spec.Targets {
+		if target.TargetRef != nil {
+			refs = append(refs, *target.TargetRef)
+		}
+	}
+	return refs
+}
+
+// ReferencesTap reports whether the job references the named PipelinewiseTap, as its tap or as one of its taps
+func (r *PipelinewiseJob) ReferencesTap(name string) bool {
+	for _, ref := range r.TapRefs() {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesTarget reports whether the job references the named PipelinewiseTarget, as its target or as one of its targets
+func (r *PipelinewiseJob) ReferencesTarget(name string) bool {
+	for _, ref := range r.TargetRefs() {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	SchemeBuilder.Register(&PipelinewiseJob{}, &PipelinewiseJobList{})
 }
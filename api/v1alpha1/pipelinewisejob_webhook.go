@@ -0,0 +1,671 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// FullTableReplication replicates the whole table on every run
+	FullTableReplication string = "FULL_TABLE"
+	// IncrementalReplication replicates rows changed since the last replication key value
+	IncrementalReplication string = "INCREMENTAL"
+	// LogBasedReplication replicates changes from the database log
+	LogBasedReplication string = "LOG_BASED"
+)
+
+// logBasedTapIDs lists the taps supporting LOG_BASED replication
+var logBasedTapIDs = []PipelinewiseTapID{
+	MySQLTapID,
+	PostgreSQLTapID,
+	OracleTapID,
+	MongoDBTapID,
+}
+
+// transformationTypes lists the supported column transformations, n is the number of characters kept by HASH-SKIP-FIRST
+var transformationTypes = []string{"SET-NULL", "HASH", "HASH-SKIP-FIRST-n", "MASK-DATE", "MASK-NUMBER", "MASK-HIDDEN"}
+
+var transformationTypePattern = regexp.MustCompile(`^(SET-NULL|HASH|HASH-SKIP-FIRST-[1-9][0-9]*|MASK-DATE|MASK-NUMBER|MASK-HIDDEN)$`)
+
+// log is for logging in this package.
+var pipelinewisejoblog = logf.Log.WithName("pipelinewisejob-resource")
+
+// referenceValidator validates a resource, reading the resources it references or is referenced by through the reader
+type referenceValidator interface {
+	runtime.Object
+	validate(ctx context.Context, reader client.Reader, operation admissionv1.Operation) error
+}
+
+// validatingHandler serves the validating webhook of a resource with the manager client injected, so validation reads
+// other resources from the cache of the manager within the context of the admission request
+type validatingHandler struct {
+	reader  client.Reader
+	decoder *admission.Decoder
+	// newObject returns an empty resource of the validated type
+	newObject func() referenceValidator
+}
+
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// InjectDecoder injects the decoder of the webhook server
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// Handle decodes the resource of the admission request and validates it, a deleted resource is read from the old object
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := h.newObject()
+	raw := req.Object
+	if req.Operation == admissionv1.Delete {
+		raw = req.OldObject
+	}
+	if err := h.decoder.DecodeRaw(raw, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := obj.validate(ctx, h.reader, req.Operation); err != nil {
+		var apiStatus apierrors.APIStatus
+		if goerrors.As(err, &apiStatus) {
+			status := apiStatus.Status()
+			return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{Allowed: false, Result: &status}}
+		}
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// registerValidatingWebhook serves the validating webhook of a resource at the path of its webhook marker
+func registerValidatingWebhook(mgr ctrl.Manager, path string, newObject func() referenceValidator) {
+	mgr.GetWebhookServer().Register(path, &webhook.Admission{Handler: &validatingHandler{reader: mgr.GetClient(), newObject: newObject}})
+}
+
+// SetupWebhookWithManager registers the PipelinewiseJob webhook to the manager
+func (r *PipelinewiseJob) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	registerValidatingWebhook(mgr, "/validate-batch-pipelinewise-v1alpha1-pipelinewisejob", func() referenceValidator {
+		return &PipelinewiseJob{}
+	})
+	return nil
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-batch-pipelinewise-v1alpha1-pipelinewisejob,mutating=false,failurePolicy=fail,groups=batch.pipelinewise,resources=pipelinewisejobs,versions=v1alpha1,name=vpipelinewisejob.kb.io
+
+// validate checks created and updated jobs, reading their referenced taps and targets through the reader when one is
+// given
+func (r *PipelinewiseJob) validate(ctx context.Context, reader client.Reader, operation admissionv1.Operation) error {
+	pipelinewisejoblog.Info("validate", "operation", operation, "name", r.Name)
+
+	if operation == admissionv1.Delete {
+		return nil
+	}
+	return r.validatePipelinewiseJob(ctx, reader)
+}
+
+func (r *PipelinewiseJob) validatePipelinewiseJob(ctx context.Context, reader client.Reader) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// Continuous jobs do not run on a schedule, neither do jobs whose taps or targets all run on schedules of their own
+	if (GetExecutionMode(r) == ScheduledExecutionMode && runsOnJobSchedule(r)) || r.Spec.Schedule != "" {
+		if _, err := cron.ParseStandard(r.Spec.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), r.Spec.Schedule, err.Error()))
+		}
+	}
+
+	if r.Spec.Encrypted && r.Spec.Secret == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("secret"), "master password secret is required to encrypt the configuration"))
+	}
+
+	allErrs = append(allErrs, validateVolume(r.Spec.Volume, specPath.Child("volume"))...)
+	allErrs = append(allErrs, validateStateEdit(r.Spec.StateEdit, specPath.Child("stateEdit"))...)
+	allErrs = append(allErrs, validateNotifications(r.Spec.Notifications, specPath.Child("notifications"))...)
+	allErrs = append(allErrs, validateMainConfig(r.Spec.MainConfig, specPath.Child("mainConfig"))...)
+
+	// Referenced taps and targets are validated on their own, tables of a referenced tap are not known here
+	tapPath := specPath.Child("tap")
+	tapsInline := false
+	if len(r.Spec.Taps) > 0 {
+		var tapErrs field.ErrorList
+		tapErrs, tapsInline = validateTaps(ctx, reader, r, specPath)
+		allErrs = append(allErrs, tapErrs...)
+	} else if r.Spec.TapRef != nil {
+		allErrs = append(allErrs, validateConnectorRef(r.Spec.TapRef, r.Spec.Tap, specPath.Child("tapRef"), tapPath)...)
+		allErrs = append(allErrs, validateResync(r.Spec.Resync, nil, specPath.Child("resync"))...)
+	} else if err := validateSingleConnector(r.Spec.Tap, tapPath, "tap"); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		tapsInline = true
+		tapInfo := getTapInfo(r)
+		allErrs = append(allErrs, validateTap(tapInfo, tapPath)...)
+		allErrs = append(allErrs, validateResync(r.Spec.Resync, []TapInfo{tapInfo}, specPath.Child("resync"))...)
+	}
+
+	targetPath := specPath.Child("target")
+	targetsInline := false
+	if len(r.Spec.Targets) > 0 {
+		var targetErrs field.ErrorList
+		targetErrs, targetsInline = validateTargets(ctx, reader, r, specPath)
+		allErrs = append(allErrs, targetErrs...)
+	} else if r.Spec.TargetRef != nil {
+		allErrs = append(allErrs, validateConnectorRef(r.Spec.TargetRef, r.Spec.Target, specPath.Child("targetRef"), targetPath)...)
+	} else if err := validateSingleConnector(r.Spec.Target, targetPath, "target"); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		targetsInline = true
+		allErrs = append(allErrs, validateTarget(getTargetInfo(r), targetPath)...)
+	}
+
+	allErrs = append(allErrs, validateStateEditTap(r, tapsInline && targetsInline, specPath.Child("stateEdit", "tap"))...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("PipelinewiseJob").GroupKind(), r.Name, allErrs)
+}
+
+// runsOnJobSchedule reports whether any tap of the job is loaded into any target on the job schedule
+func runsOnJobSchedule(r *PipelinewiseJob) bool {
+	tapsUnscheduled := len(r.Spec.Taps) == 0
+	for _, tap := range r.Spec.Taps {
+		tapsUnscheduled = tapsUnscheduled || tap.Schedule == ""
+	}
+	targetsUnscheduled := len(r.Spec.Targets) == 0
+	for _, target := range r.Spec.Targets {
+		targetsUnscheduled = targetsUnscheduled || target.Schedule == ""
+	}
+	return tapsUnscheduled && targetsUnscheduled
+}
+
+// validateTaps checks every tap of a job with several taps and reports whether all of them are configured inline.
+// Tap ids must be unique, since every tap is rendered into a file named after its id. Tables and ids of referenced
+// taps are not known here
+func validateTaps(ctx context.Context, reader client.Reader, r *PipelinewiseJob, specPath *field.Path) (field.ErrorList, bool) {
+	var allErrs field.ErrorList
+	tapsPath := specPath.Child("taps")
+	if !reflect.ValueOf(r.Spec.Tap).IsZero() {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("tap"), fmt.Sprintf("may not be set together with %v", tapsPath.String())))
+	}
+	if r.Spec.TapRef != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("tapRef"), fmt.Sprintf("may not be set together with %v", tapsPath.String())))
+	}
+	if GetExecutionMode(r) == ContinuousExecutionMode {
+		allErrs = append(allErrs, field.Forbidden(tapsPath, "several taps are only supported in Scheduled mode"))
+	}
+
+	tapInfos := []TapInfo{}
+	tapIDs := map[PipelinewiseTapID]bool{}
+	allInline := true
+	for tapNth, tap := range r.Spec.Taps {
+		tapPath := tapsPath.Index(tapNth)
+		if tap.Schedule != "" {
+			if _, err := cron.ParseStandard(tap.Schedule); err != nil {
+				allErrs = append(allErrs, field.Invalid(tapPath.Child("schedule"), tap.Schedule, err.Error()))
+			}
+		}
+		if tap.TapRef != nil {
+			allInline = false
+			allErrs = append(allErrs, validateConnectorRef(tap.TapRef, tap.TapSpec, tapPath.Child("tapRef"), tapPath)...)
+			// Referenced taps render into files named after their id as well
+			if tapInfo := referencedTapInfo(ctx, reader, r.Namespace, tap.TapRef); tapInfo != nil {
+				if tapIDs[tapInfo.ID()] {
+					allErrs = append(allErrs, field.Duplicate(tapPath.Child("tapRef"), tapInfo.ID()))
+				}
+				tapIDs[tapInfo.ID()] = true
+			}
+			continue
+		}
+		if err := validateSingleConnector(tap.TapSpec, tapPath, "tap"); err != nil {
+			allInline = false
+			allErrs = append(allErrs, err)
+			continue
+		}
+		tapInfo := tapSpecInfo(tap.TapSpec)
+		if tapIDs[tapInfo.ID()] {
+			allErrs = append(allErrs, field.Duplicate(tapPath, tapInfo.ID()))
+		}
+		tapIDs[tapInfo.ID()] = true
+		allErrs = append(allErrs, validateTap(tapInfo, tapPath)...)
+		tapInfos = append(tapInfos, tapInfo)
+	}
+	if !allInline {
+		tapInfos = nil
+	}
+	allErrs = append(allErrs, validateResync(r.Spec.Resync, tapInfos, specPath.Child("resync"))...)
+
+	return allErrs, allInline
+}
+
+// validateTargets checks every target of a job with several targets and reports whether all of them are configured
+// inline. Target ids must be unique, since they tell the copies of a tap apart
+func validateTargets(ctx context.Context, reader client.Reader, r *PipelinewiseJob, specPath *field.Path) (field.ErrorList, bool) {
+	var allErrs field.ErrorList
+	targetsPath := specPath.Child("targets")
+	if !reflect.ValueOf(r.Spec.Target).IsZero() {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("target"), fmt.Sprintf("may not be set together with %v", targetsPath.String())))
+	}
+	if r.Spec.TargetRef != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("targetRef"), fmt.Sprintf("may not be set together with %v", targetsPath.String())))
+	}
+	if GetExecutionMode(r) == ContinuousExecutionMode {
+		allErrs = append(allErrs, field.Forbidden(targetsPath, "several targets are only supported in Scheduled mode"))
+	}
+
+	targetIDs := map[PipelinewiseTargetID]bool{}
+	allInline := true
+	for targetNth, target := range r.Spec.Targets {
+		targetPath := targetsPath.Index(targetNth)
+		if target.Schedule != "" {
+			if _, err := cron.ParseStandard(target.Schedule); err != nil {
+				allErrs = append(allErrs, field.Invalid(targetPath.Child("schedule"), target.Schedule, err.Error()))
+			}
+		}
+		if target.TargetRef != nil {
+			allInline = false
+			allErrs = append(allErrs, validateConnectorRef(target.TargetRef, target.TargetSpec, targetPath.Child("targetRef"), targetPath)...)
+			if targetInfo := referencedTargetInfo(ctx, reader, r.Namespace, target.TargetRef); targetInfo != nil {
+				if targetIDs[targetInfo.ID()] {
+					allErrs = append(allErrs, field.Duplicate(targetPath.Child("targetRef"), targetInfo.ID()))
+				}
+				targetIDs[targetInfo.ID()] = true
+			}
+			continue
+		}
+		if err := validateSingleConnector(target.TargetSpec, targetPath, "target"); err != nil {
+			allInline = false
+			allErrs = append(allErrs, err)
+			continue
+		}
+		targetInfo := targetSpecInfo(target.TargetSpec)
+		if targetIDs[targetInfo.ID()] {
+			allErrs = append(allErrs, field.Duplicate(targetPath, targetInfo.ID()))
+		}
+		targetIDs[targetInfo.ID()] = true
+		allErrs = append(allErrs, validateTarget(targetInfo, targetPath)...)
+	}
+
+	return allErrs, allInline
+}
+
+// validateStateEditTap ensures the state edit of a job with several taps or targets selects one of its tap ids.
+// Ids are only checked when every tap and target is configured inline
+func validateStateEditTap(r *PipelinewiseJob, inline bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	stateEdit := r.Spec.StateEdit
+	if stateEdit == nil || (len(r.Spec.Taps) == 0 && len(r.Spec.Targets) == 0) {
+		return allErrs
+	}
+
+	if stateEdit.Tap == "" {
+		return append(allErrs, field.Required(fldPath, "tap is required for jobs with several taps or targets"))
+	}
+	if !inline {
+		return allErrs
+	}
+	for _, pipeline := range GetPipelines(r) {
+		if string(pipeline.TapID()) == stateEdit.Tap {
+			return allErrs
+		}
+	}
+	return append(allErrs, field.NotFound(fldPath, stateEdit.Tap))
+}
+
+// validateUnreferenced rejects the deletion of a tap or target while jobs of its namespace still reference it.
+// Jobs are listed from the cache of the manager
+func validateUnreferenced(ctx context.Context, reader client.Reader, resource schema.GroupResource, namespace, name string, references func(*PipelinewiseJob) bool) error {
+	var pwJobs PipelinewiseJobList
+	if err := reader.List(ctx, &pwJobs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	names := []string{}
+	for i := range pwJobs.Items {
+		if references(&pwJobs.Items[i]) {
+			names = append(names, pwJobs.Items[i].Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return apierrors.NewForbidden(resource, name, fmt.Errorf("still referenced by PipelinewiseJob %v", strings.Join(names, ", ")))
+}
+
+// referencedTapInfo returns the tap of a referenced PipelinewiseTap, nil while it is not known
+func referencedTapInfo(ctx context.Context, reader client.Reader, namespace string, ref *corev1.LocalObjectReference) TapInfo {
+	var tap PipelinewiseTap
+	if reader == nil || reader.Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: ref.Name}, &tap) != nil {
+		return nil
+	}
+	return tapSpecInfo(tap.Spec.TapSpec)
+}
+
+// referencedTargetInfo returns the target of a referenced PipelinewiseTarget, nil while it is not known
+func referencedTargetInfo(ctx context.Context, reader client.Reader, namespace string, ref *corev1.LocalObjectReference) TargetInfo {
+	var target PipelinewiseTarget
+	if reader == nil || reader.Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: ref.Name}, &target) != nil {
+		return nil
+	}
+	return targetSpecInfo(target.Spec.TargetSpec)
+}
+
+// validateTap checks the replication settings of the tap and its Secret references
+func validateTap(tapInfo TapInfo, tapPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateTapSchemas(tapInfo, tapPath)...)
+	allErrs = append(allErrs, validateSecretRefs(tapInfo.GetConnection(), tapPath.Child(tapConnectorJSONName(tapInfo)).Child("db_conn"))...)
+	return allErrs
+}
+
+// validateTarget checks the Secret references of the target
+func validateTarget(targetInfo TargetInfo, targetPath *field.Path) field.ErrorList {
+	return validateSecretRefs(targetInfo.GetConnection(), targetPath.Child(targetConnectorJSONName(targetInfo)))
+}
+
+// validateConnectorRef ensures a referenced tap or target names the referenced resource and is not configured inline as well
+func validateConnectorRef(ref *corev1.LocalObjectReference, inline interface{}, refPath, inlinePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(refPath.Child("name"), "name of the referenced resource is required"))
+	}
+	if !reflect.ValueOf(inline).IsZero() {
+		allErrs = append(allErrs, field.Forbidden(inlinePath, fmt.Sprintf("may not be set together with %v", refPath.String())))
+	}
+	return allErrs
+}
+
+// validateSingleConnector ensures exactly one connector field of a TapSpec or TargetSpec is set
+func validateSingleConnector(spec interface{}, fldPath *field.Path, kind string) *field.Error {
+	specVal := reflect.ValueOf(spec)
+	configured := []string{}
+	for fieldNth := 0; fieldNth < specVal.NumField(); fieldNth++ {
+		if !specVal.Field(fieldNth).IsNil() {
+			configured = append(configured, jsonFieldName(specVal.Type().Field(fieldNth)))
+		}
+	}
+
+	switch len(configured) {
+	case 0:
+		return field.Required(fldPath, fmt.Sprintf("exactly one %v must be configured", kind))
+	case 1:
+		return nil
+	default:
+		return field.Invalid(fldPath, strings.Join(configured, ", "), fmt.Sprintf("exactly one %v must be configured", kind))
+	}
+}
+
+// validateVolume ensures an existing claim is not combined with settings which only apply to generated claims
+func validateVolume(volume *VolumeSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if volume == nil {
+		return allErrs
+	}
+
+	if volume.Size != nil && volume.Size.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("size"), volume.Size.String(), "size must be positive"))
+	}
+	if volume.ExistingClaimName != "" {
+		if volume.Size != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("size"), "may not be set together with existingClaimName"))
+		}
+		if volume.StorageClassName != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("storageClassName"), "may not be set together with existingClaimName"))
+		}
+		if len(volume.AccessModes) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("accessModes"), "may not be set together with existingClaimName"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateResync ensures a resync request carries a token and only lists tables replicated by the taps.
+// Tables are not checked while the taps are not known
+func validateResync(resync *ResyncSpec, tapInfos []TapInfo, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if resync == nil {
+		return allErrs
+	}
+
+	if resync.Token == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("token"), "token is required to request a resync"))
+	}
+	if tapInfos == nil {
+		return allErrs
+	}
+	tables := []string{}
+	for _, tapInfo := range tapInfos {
+		tables = append(tables, tapTableNames(tapInfo)...)
+	}
+	for tableNth, table := range resync.Tables {
+		if !containsTable(tables, table) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("tables").Index(tableNth), table))
+		}
+	}
+
+	return allErrs
+}
+
+// validateStateEdit ensures a state edit request carries a token and every bookmark is set on a named stream as a JSON object
+func validateStateEdit(stateEdit *StateEditSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if stateEdit == nil {
+		return allErrs
+	}
+
+	if stateEdit.Token == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("token"), "token is required to request a state edit"))
+	}
+	if !stateEdit.Reset && len(stateEdit.Streams) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("streams"), "streams are required unless the state is reset"))
+	}
+	streams := map[string]bool{}
+	for streamNth, stream := range stateEdit.Streams {
+		streamPath := fldPath.Child("streams").Index(streamNth)
+		if stream.Stream == "" {
+			allErrs = append(allErrs, field.Required(streamPath.Child("stream"), "stream id is required"))
+		} else if streams[stream.Stream] {
+			allErrs = append(allErrs, field.Duplicate(streamPath.Child("stream"), stream.Stream))
+		}
+		streams[stream.Stream] = true
+
+		if stream.Bookmark != nil {
+			bookmark := map[string]interface{}{}
+			if err := json.Unmarshal(stream.Bookmark.Raw, &bookmark); err != nil {
+				allErrs = append(allErrs, field.Invalid(streamPath.Child("bookmark"), string(stream.Bookmark.Raw), "bookmark must be a JSON object"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateNotifications ensures every receiver references its webhook URL
+func validateNotifications(notifications *NotificationSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if notifications == nil {
+		return allErrs
+	}
+
+	for receiverNth, receiver := range notifications.Receivers {
+		if receiver.URLFrom.SecretKeyRef == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("receivers").Index(receiverNth).Child("urlFrom", "secretKeyRef"), "webhook URL must be referenced from a Secret"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateMainConfig ensures every alert handler sets its credential, either inline or referenced from a Secret
+func validateMainConfig(mainConfig *MainConfigSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if mainConfig == nil || mainConfig.AlertHandlers == nil {
+		return allErrs
+	}
+
+	handlersPath := fldPath.Child("alertHandlers")
+	if slack := mainConfig.AlertHandlers.Slack; slack != nil && slack.Token == "" && slack.TokenFrom == nil {
+		allErrs = append(allErrs, field.Required(handlersPath.Child("slack", "token"), "token or token_from is required"))
+	}
+	if victorOps := mainConfig.AlertHandlers.VictorOps; victorOps != nil && victorOps.BaseURL == "" && victorOps.BaseURLFrom == nil {
+		allErrs = append(allErrs, field.Required(handlersPath.Child("victorops", "base_url"), "base_url or base_url_from is required"))
+	}
+	allErrs = append(allErrs, validateSecretRefs(mainConfig.AlertHandlers, handlersPath)...)
+
+	return allErrs
+}
+
+// tapTableNames lists the tables replicated by the tap as `<source_schema>.<table_name>`
+func tapTableNames(tapInfo TapInfo) []string {
+	tables := []string{}
+	switch schemas := tapInfo.GetSchemas().(type) {
+	case []TapSchemaSpec:
+		for _, schema := range schemas {
+			for _, table := range schema.Tables {
+				tables = append(tables, fmt.Sprintf("%v.%v", schema.Source, table.TableName))
+			}
+		}
+	case []S3CSVTapSchemaSpec:
+		for _, schema := range schemas {
+			for _, table := range schema.Tables {
+				tables = append(tables, fmt.Sprintf("%v.%v", schema.Source, table.TableName))
+			}
+		}
+	}
+	return tables
+}
+
+func containsTable(tables []string, table string) bool {
+	for _, item := range tables {
+		if item == table {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTapSchemas checks the replication settings and transformations of every table
+func validateTapSchemas(tapInfo TapInfo, tapPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	supportsLogBased := false
+	for _, tapID := range logBasedTapIDs {
+		if tapInfo.ConnectorID() == string(tapID) {
+			supportsLogBased = true
+		}
+	}
+
+	schemasPath := tapPath.Child(tapConnectorJSONName(tapInfo)).Child("schemas")
+	switch schemas := tapInfo.GetSchemas().(type) {
+	case []TapSchemaSpec:
+		for schemaNth, schema := range schemas {
+			for tableNth, table := range schema.Tables {
+				tablePath := schemasPath.Index(schemaNth).Child("tables").Index(tableNth)
+				switch table.ReplicationMethod {
+				case FullTableReplication:
+				case IncrementalReplication:
+					if table.ReplicationKey == "" {
+						allErrs = append(allErrs, field.Required(tablePath.Child("replication_key"), "replication_key is required for INCREMENTAL replication"))
+					}
+				case LogBasedReplication:
+					if !supportsLogBased {
+						allErrs = append(allErrs, field.Invalid(tablePath.Child("replication_method"), table.ReplicationMethod, fmt.Sprintf("LOG_BASED replication is not supported by %v", tapInfo.Type())))
+					}
+				default:
+					allErrs = append(allErrs, field.NotSupported(tablePath.Child("replication_method"), table.ReplicationMethod, []string{FullTableReplication, IncrementalReplication, LogBasedReplication}))
+				}
+				allErrs = append(allErrs, validateTransformations(table.Transformations, tablePath.Child("transformations"))...)
+			}
+		}
+	case []S3CSVTapSchemaSpec:
+		// S3 CSV tables are defined by their file mapping and do not have a replication method
+		for schemaNth, schema := range schemas {
+			for tableNth, table := range schema.Tables {
+				tablePath := schemasPath.Index(schemaNth).Child("tables").Index(tableNth)
+				allErrs = append(allErrs, validateTransformations(table.Transformations, tablePath.Child("transformations"))...)
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateTransformations checks the transformation types and their conditions
+func validateTransformations(transformations []TransformationSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for transformationNth, transformation := range transformations {
+		transformationPath := fldPath.Index(transformationNth)
+		if transformation.Column == "" {
+			allErrs = append(allErrs, field.Required(transformationPath.Child("column"), "column to transform is required"))
+		}
+		if !transformationTypePattern.MatchString(transformation.Type) {
+			allErrs = append(allErrs, field.NotSupported(transformationPath.Child("type"), transformation.Type, transformationTypes))
+		}
+		for conditionNth, condition := range transformation.When {
+			conditionPath := transformationPath.Child("when").Index(conditionNth)
+			if condition.Column == "" {
+				allErrs = append(allErrs, field.Required(conditionPath.Child("column"), "column of the condition is required"))
+			}
+			if (condition.Equals == nil) == (condition.RegexMatch == "") {
+				allErrs = append(allErrs, field.Invalid(conditionPath, condition.Column, "exactly one of equals or regex_match must be set"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// tapConnectorJSONName returns the TapSpec field name holding the given tap
+func tapConnectorJSONName(tapInfo TapInfo) string {
+	return connectorJSONName(reflect.TypeOf(TapSpec{}), reflect.TypeOf(tapInfo))
+}
+
+// targetConnectorJSONName returns the TargetSpec field name holding the given target
+func targetConnectorJSONName(targetInfo TargetInfo) string {
+	return connectorJSONName(reflect.TypeOf(TargetSpec{}), reflect.TypeOf(targetInfo))
+}
+
+func connectorJSONName(specType reflect.Type, connectorType reflect.Type) string {
+	for fieldNth := 0; fieldNth < specType.NumField(); fieldNth++ {
+		if specType.Field(fieldNth).Type == connectorType {
+			return jsonFieldName(specType.Field(fieldNth))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,692 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("PipelinewiseJob Webhook", func() {
+	type TestCase struct {
+		Schedule      string
+		Mode          ExecutionMode
+		Encrypted     bool
+		Volume        *VolumeSpec
+		Resync        *ResyncSpec
+		StateEdit     *StateEditSpec
+		Notifications *NotificationSpec
+		MainConfig    *MainConfigSpec
+		TapRef        *corev1.LocalObjectReference
+		TargetRef     *corev1.LocalObjectReference
+		Taps          []JobTapSpec
+		Targets       []JobTargetSpec
+		Tap           TapSpec
+		Target        TargetSpec
+		ErrorMessage  string
+	}
+
+	mysqlTap := func(tables ...TapTableSpec) TapSpec {
+		return TapSpec{
+			MySQL: &MySQLTapSpec{
+				Schemas: []TapSchemaSpec{
+					{
+						Source: "source",
+						Target: "target",
+						Tables: tables,
+					},
+				},
+				Connection: MySQLTapConnectionSpec{
+					Host:   "mysql",
+					DBName: "db",
+				},
+			},
+		}
+	}
+	postgresTarget := TargetSpec{
+		PostgreSQL: &PostgreSQLTargetSpec{
+			Host: "postgresql",
+		},
+	}
+	fullTable := TapTableSpec{
+		TableName:         "table",
+		ReplicationMethod: FullTableReplication,
+	}
+	otherTap := mysqlTap(TapTableSpec{
+		TableName:         "other_table",
+		ReplicationMethod: FullTableReplication,
+	})
+	otherTap.MySQL.Connection.DBName = "other"
+	archiveTarget := TargetSpec{
+		S3CSV: &S3CSVTargetSpec{
+			S3Bucket: "archive",
+		},
+	}
+
+	DescribeTable("Validating PipelinewiseJob",
+		func(testCase TestCase) {
+			pwJob := &PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "validation",
+					Namespace: "default",
+				},
+				Spec: PipelinewiseJobSpec{
+					Schedule:      testCase.Schedule,
+					Mode:          testCase.Mode,
+					Encrypted:     testCase.Encrypted,
+					Volume:        testCase.Volume,
+					Resync:        testCase.Resync,
+					StateEdit:     testCase.StateEdit,
+					Notifications: testCase.Notifications,
+					MainConfig:    testCase.MainConfig,
+					Tap:           testCase.Tap,
+					Target:        testCase.Target,
+					TapRef:        testCase.TapRef,
+					TargetRef:     testCase.TargetRef,
+					Taps:          testCase.Taps,
+					Targets:       testCase.Targets,
+				},
+			}
+
+			err := pwJob.validate(context.Background(), nil, admissionv1.Create)
+			if testCase.ErrorMessage == "" {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(pwJob.validate(context.Background(), nil, admissionv1.Update)).Should(Succeed())
+				return
+			}
+			Expect(apierrors.IsInvalid(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring(testCase.ErrorMessage))
+		},
+		Entry("Valid job", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: mysqlTap(fullTable, TapTableSpec{
+				TableName:         "incremental",
+				ReplicationMethod: IncrementalReplication,
+				ReplicationKey:    "updated_at",
+			}, TapTableSpec{
+				TableName:         "log",
+				ReplicationMethod: LogBasedReplication,
+			}),
+			Target: postgresTarget,
+		}),
+		Entry("S3 CSV tap without replication method", TestCase{
+			Schedule: "*/5 * * * *",
+			Tap: TapSpec{
+				S3CSV: &S3CSVTapSpec{
+					Connection: S3CSVTapConnectionSpec{
+						Bucket: "bucket",
+					},
+				},
+			},
+			Target: postgresTarget,
+		}),
+		Entry("Invalid cron expression", TestCase{
+			Schedule:     "every day",
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.schedule",
+		}),
+		Entry("Continuous job without schedule", TestCase{
+			Mode:   ContinuousExecutionMode,
+			Tap:    mysqlTap(fullTable),
+			Target: postgresTarget,
+		}),
+		Entry("Scheduled job without schedule", TestCase{
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.schedule",
+		}),
+		Entry("Missing tap", TestCase{
+			Schedule:     "0 0 * * *",
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap: Required value: exactly one tap must be configured",
+		}),
+		Entry("Multiple taps", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: TapSpec{
+				MySQL: mysqlTap(fullTable).MySQL,
+				PostgreSQL: &PostgreSQLTapSpec{
+					Schemas: []TapSchemaSpec{},
+				},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap: Invalid value: \"mysql, postgres\"",
+		}),
+		Entry("Missing target", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			ErrorMessage: "spec.target: Required value: exactly one target must be configured",
+		}),
+		Entry("Multiple targets", TestCase{
+			Schedule: "0 0 * * *",
+			Tap:      mysqlTap(fullTable),
+			Target: TargetSpec{
+				PostgreSQL: postgresTarget.PostgreSQL,
+				S3CSV: &S3CSVTargetSpec{
+					S3Bucket: "bucket",
+				},
+			},
+			ErrorMessage: "spec.target: Invalid value: \"postgresql, s3_csv\"",
+		}),
+		Entry("Unknown replication method", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: mysqlTap(TapTableSpec{
+				TableName:         "table",
+				ReplicationMethod: "SNAPSHOT",
+			}),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap.mysql.schemas[0].tables[0].replication_method: Unsupported value: \"SNAPSHOT\"",
+		}),
+		Entry("Incremental replication without replication key", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: mysqlTap(TapTableSpec{
+				TableName:         "table",
+				ReplicationMethod: IncrementalReplication,
+			}),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap.mysql.schemas[0].tables[0].replication_key: Required value",
+		}),
+		Entry("Valid transformations", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: mysqlTap(TapTableSpec{
+				TableName:         "table",
+				ReplicationMethod: FullTableReplication,
+				Transformations: []TransformationSpec{
+					{Column: "email", Type: "HASH-SKIP-FIRST-3"},
+					{Column: "phone", Type: "MASK-HIDDEN", When: []TransformationConditionSpec{
+						{Column: "country", RegexMatch: "^NL$"},
+						{Column: "is_test", Equals: &apiextensionsv1.JSON{Raw: []byte("true")}},
+					}},
+				},
+			}),
+			Target: postgresTarget,
+		}),
+		Entry("Unknown transformation type", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: mysqlTap(TapTableSpec{
+				TableName:         "table",
+				ReplicationMethod: FullTableReplication,
+				Transformations: []TransformationSpec{
+					{Column: "email", Type: "HASH-SKIP-FIRST-n"},
+				},
+			}),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap.mysql.schemas[0].tables[0].transformations[0].type: Unsupported value: \"HASH-SKIP-FIRST-n\"",
+		}),
+		Entry("Transformation condition without value", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: TapSpec{
+				S3CSV: &S3CSVTapSpec{
+					Schemas: []S3CSVTapSchemaSpec{
+						{
+							Tables: []S3CSVTapTableSpec{
+								{
+									TableName: "table",
+									Transformations: []TransformationSpec{
+										{Column: "email", Type: "SET-NULL", When: []TransformationConditionSpec{
+											{Column: "country"},
+										}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap.s3_csv.schemas[0].tables[0].transformations[0].when[0]: Invalid value: \"country\": exactly one of equals or regex_match must be set",
+		}),
+		Entry("Credentials referenced from a Secret", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: TapSpec{
+				MySQL: &MySQLTapSpec{
+					Connection: MySQLTapConnectionSpec{
+						Host:         "mysql",
+						PasswordFrom: &ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "mysql"}, Key: "password"}},
+					},
+				},
+			},
+			Target: postgresTarget,
+		}),
+		Entry("Credential both inline and referenced", TestCase{
+			Schedule: "0 0 * * *",
+			Tap:      mysqlTap(fullTable),
+			Target: TargetSpec{
+				PostgreSQL: &PostgreSQLTargetSpec{
+					Password:     "inline",
+					PasswordFrom: &ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "postgresql"}, Key: "password"}},
+				},
+			},
+			ErrorMessage: "spec.target.postgresql.password_from: Forbidden: may not be set together with password",
+		}),
+		Entry("Credential reference without Secret key", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: TapSpec{
+				GoogleAnalytics: &GoogleAnalyticsTapSpec{
+					Connection: GoogleAnalyticsTapConnectionSpec{
+						OauthCredentials: &GoogleAnalyticsOauthCredentials{
+							RefreshTokenFrom: &ValueFromSource{},
+						},
+					},
+				},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap.google_analytics.db_conn.oauth_credentials.refresh_token_from.secretKeyRef: Required value",
+		}),
+		Entry("Encryption without master password", TestCase{
+			Schedule:     "0 0 * * *",
+			Encrypted:    true,
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.secret: Required value",
+		}),
+		Entry("Existing state volume with storage settings", TestCase{
+			Schedule: "0 0 * * *",
+			Volume: &VolumeSpec{
+				ExistingClaimName: "state",
+				Size:              resource.NewQuantity(1<<30, resource.BinarySI),
+			},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.volume.size: Forbidden: may not be set together with existingClaimName",
+		}),
+		Entry("Resync of replicated tables", TestCase{
+			Schedule: "0 0 * * *",
+			Resync:   &ResyncSpec{Token: "1", Tables: []string{"source.table"}},
+			Tap:      mysqlTap(fullTable),
+			Target:   postgresTarget,
+		}),
+		Entry("Resync of a table which is not replicated", TestCase{
+			Schedule:     "0 0 * * *",
+			Resync:       &ResyncSpec{Token: "1", Tables: []string{"source.unknown"}},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.resync.tables[0]: Not found: \"source.unknown\"",
+		}),
+		Entry("Resync without token", TestCase{
+			Schedule:     "0 0 * * *",
+			Resync:       &ResyncSpec{},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.resync.token",
+		}),
+		Entry("State edit of a stream bookmark", TestCase{
+			Schedule: "0 0 * * *",
+			StateEdit: &StateEditSpec{
+				Token: "1",
+				Streams: []StreamBookmarkSpec{
+					{Stream: "source-table", Bookmark: &apiextensionsv1.JSON{Raw: []byte(`{"log_file":"mysql-bin.000003","log_pos":4}`)}},
+					{Stream: "source-incremental"},
+				},
+			},
+			Tap:    mysqlTap(fullTable),
+			Target: postgresTarget,
+		}),
+		Entry("State edit without reset or streams", TestCase{
+			Schedule:     "0 0 * * *",
+			StateEdit:    &StateEditSpec{Token: "1"},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.stateEdit.streams",
+		}),
+		Entry("State edit with a bookmark which is not an object", TestCase{
+			Schedule: "0 0 * * *",
+			StateEdit: &StateEditSpec{
+				Token:   "1",
+				Streams: []StreamBookmarkSpec{{Stream: "source-table", Bookmark: &apiextensionsv1.JSON{Raw: []byte(`4`)}}},
+			},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.stateEdit.streams[0].bookmark",
+		}),
+		Entry("Notification receiver without webhook URL", TestCase{
+			Schedule: "0 0 * * *",
+			Notifications: &NotificationSpec{
+				Receivers: []NotificationReceiverSpec{{Type: SlackNotificationReceiver}},
+			},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.notifications.receivers[0].urlFrom.secretKeyRef",
+		}),
+		Entry("Slack alert handler with referenced token", TestCase{
+			Schedule: "0 0 * * *",
+			MainConfig: &MainConfigSpec{
+				AlertHandlers: &AlertHandlersSpec{
+					Slack: &SlackAlertHandlerSpec{
+						TokenFrom: &ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "alerts"},
+							Key:                  "slack-token",
+						}},
+						Channel: "#alerts",
+					},
+				},
+			},
+			Tap:    mysqlTap(fullTable),
+			Target: postgresTarget,
+		}),
+		Entry("Slack alert handler without token", TestCase{
+			Schedule: "0 0 * * *",
+			MainConfig: &MainConfigSpec{
+				AlertHandlers: &AlertHandlersSpec{
+					Slack: &SlackAlertHandlerSpec{Channel: "#alerts"},
+				},
+			},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.mainConfig.alertHandlers.slack.token",
+		}),
+		Entry("VictorOps alert handler with base URL set twice", TestCase{
+			Schedule: "0 0 * * *",
+			MainConfig: &MainConfigSpec{
+				AlertHandlers: &AlertHandlersSpec{
+					VictorOps: &VictorOpsAlertHandlerSpec{
+						BaseURL: "https://alert.victorops.com/integrations/generic/1/alert/key",
+						BaseURLFrom: &ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "alerts"},
+							Key:                  "victorops-url",
+						}},
+						RoutingKey: "pipelinewise",
+					},
+				},
+			},
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			ErrorMessage: "spec.mainConfig.alertHandlers.victorops.base_url_from",
+		}),
+		Entry("Referenced tap and target", TestCase{
+			Schedule:  "0 0 * * *",
+			TapRef:    &corev1.LocalObjectReference{Name: "orders-db"},
+			TargetRef: &corev1.LocalObjectReference{Name: "warehouse"},
+			Resync:    &ResyncSpec{Token: "1", Tables: []string{"source.table"}},
+		}),
+		Entry("Referenced target configured inline as well", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			TargetRef:    &corev1.LocalObjectReference{Name: "warehouse"},
+			ErrorMessage: "spec.target: Forbidden: may not be set together with spec.targetRef",
+		}),
+		Entry("Referenced tap without name", TestCase{
+			Schedule:     "0 0 * * *",
+			TapRef:       &corev1.LocalObjectReference{},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tapRef.name: Required value",
+		}),
+		Entry("Several taps on the job schedule and their own", TestCase{
+			Schedule: "0 0 * * *",
+			Taps: []JobTapSpec{
+				{TapSpec: mysqlTap(fullTable)},
+				{TapSpec: otherTap, Schedule: "*/15 * * * *"},
+			},
+			Target:    postgresTarget,
+			Resync:    &ResyncSpec{Token: "1", Tables: []string{"source.other_table"}},
+			StateEdit: &StateEditSpec{Token: "1", Tap: "mysql-other", Reset: true},
+		}),
+		Entry("Several taps all on their own schedule", TestCase{
+			Taps: []JobTapSpec{
+				{TapSpec: mysqlTap(fullTable), Schedule: "0 0 * * *"},
+				{TapRef: &corev1.LocalObjectReference{Name: "orders-db"}, Schedule: "*/15 * * * *"},
+			},
+			Target: postgresTarget,
+		}),
+		Entry("Several taps without job schedule", TestCase{
+			Taps: []JobTapSpec{
+				{TapSpec: mysqlTap(fullTable)},
+				{TapSpec: otherTap, Schedule: "*/15 * * * *"},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.schedule",
+		}),
+		Entry("Several taps with the same tap id", TestCase{
+			Schedule: "0 0 * * *",
+			Taps: []JobTapSpec{
+				{TapSpec: mysqlTap(fullTable)},
+				{TapSpec: mysqlTap(fullTable)},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: `spec.taps[1]: Duplicate value: "mysql-db"`,
+		}),
+		Entry("Several taps together with a single tap", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Taps:         []JobTapSpec{{TapSpec: otherTap}},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.tap: Forbidden: may not be set together with spec.taps",
+		}),
+		Entry("Several taps in continuous mode", TestCase{
+			Mode:         ContinuousExecutionMode,
+			Taps:         []JobTapSpec{{TapSpec: mysqlTap(fullTable)}, {TapSpec: otherTap}},
+			Target:       postgresTarget,
+			ErrorMessage: "spec.taps: Forbidden: several taps are only supported in Scheduled mode",
+		}),
+		Entry("Resync of a table none of several taps replicates", TestCase{
+			Schedule:     "0 0 * * *",
+			Taps:         []JobTapSpec{{TapSpec: mysqlTap(fullTable)}, {TapSpec: otherTap}},
+			Target:       postgresTarget,
+			Resync:       &ResyncSpec{Token: "1", Tables: []string{"source.unknown"}},
+			ErrorMessage: "spec.resync.tables[0]: Not found",
+		}),
+		Entry("State edit of several taps without tap", TestCase{
+			Schedule:     "0 0 * * *",
+			Taps:         []JobTapSpec{{TapSpec: mysqlTap(fullTable)}, {TapSpec: otherTap}},
+			Target:       postgresTarget,
+			StateEdit:    &StateEditSpec{Token: "1", Reset: true},
+			ErrorMessage: "spec.stateEdit.tap: Required value",
+		}),
+		Entry("Tap loaded into several targets", TestCase{
+			Schedule: "0 0 * * *",
+			Tap:      mysqlTap(fullTable),
+			Targets: []JobTargetSpec{
+				{TargetSpec: postgresTarget},
+				{TargetSpec: archiveTarget, Schedule: "0 3 * * *"},
+			},
+			StateEdit: &StateEditSpec{Token: "1", Tap: "mysql-db-s3-csv-archive", Reset: true},
+		}),
+		Entry("Several targets all on their own schedule", TestCase{
+			Tap: mysqlTap(fullTable),
+			Targets: []JobTargetSpec{
+				{TargetSpec: postgresTarget, Schedule: "0 * * * *"},
+				{TargetRef: &corev1.LocalObjectReference{Name: "warehouse"}, Schedule: "0 3 * * *"},
+			},
+		}),
+		Entry("Several targets without job schedule", TestCase{
+			Tap: mysqlTap(fullTable),
+			Targets: []JobTargetSpec{
+				{TargetSpec: postgresTarget},
+				{TargetSpec: archiveTarget, Schedule: "0 3 * * *"},
+			},
+			ErrorMessage: "spec.schedule",
+		}),
+		Entry("Several targets with the same target id", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Targets:      []JobTargetSpec{{TargetSpec: archiveTarget}, {TargetSpec: archiveTarget}},
+			ErrorMessage: `spec.targets[1]: Duplicate value: "s3-csv-archive"`,
+		}),
+		Entry("Several targets together with a single target", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Target:       postgresTarget,
+			Targets:      []JobTargetSpec{{TargetSpec: archiveTarget}},
+			ErrorMessage: "spec.target: Forbidden: may not be set together with spec.targets",
+		}),
+		Entry("Several targets in continuous mode", TestCase{
+			Mode:         ContinuousExecutionMode,
+			Tap:          mysqlTap(fullTable),
+			Targets:      []JobTargetSpec{{TargetSpec: postgresTarget}, {TargetSpec: archiveTarget}},
+			ErrorMessage: "spec.targets: Forbidden: several targets are only supported in Scheduled mode",
+		}),
+		Entry("Several targets with an invalid schedule", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Targets:      []JobTargetSpec{{TargetSpec: postgresTarget}, {TargetSpec: archiveTarget, Schedule: "daily"}},
+			ErrorMessage: "spec.targets[1].schedule: Invalid value",
+		}),
+		Entry("State edit of a tap id not fanned out to the targets", TestCase{
+			Schedule:     "0 0 * * *",
+			Tap:          mysqlTap(fullTable),
+			Targets:      []JobTargetSpec{{TargetSpec: postgresTarget}, {TargetSpec: archiveTarget}},
+			StateEdit:    &StateEditSpec{Token: "1", Tap: "mysql-db", Reset: true},
+			ErrorMessage: `spec.stateEdit.tap: Not found: "mysql-db"`,
+		}),
+		Entry("Log based replication on unsupported tap", TestCase{
+			Schedule: "0 0 * * *",
+			Tap: TapSpec{
+				Snowflake: &SnowflakeTapSpec{
+					Schemas: []TapSchemaSpec{
+						{
+							Source: "source",
+							Target: "target",
+							Tables: []TapTableSpec{
+								{
+									TableName:         "table",
+									ReplicationMethod: LogBasedReplication,
+								},
+							},
+						},
+					},
+				},
+			},
+			Target:       postgresTarget,
+			ErrorMessage: "LOG_BASED replication is not supported by tap-snowflake",
+		}),
+	)
+
+	It("Should validate shared taps and targets like inline ones", func() {
+		tap := &PipelinewiseTap{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-db", Namespace: "default"},
+			Spec:       PipelinewiseTapSpec{TapSpec: mysqlTap(fullTable)},
+		}
+		Expect(tap.validate(context.Background(), nil, admissionv1.Create)).Should(Succeed())
+		tap.Spec.MySQL.Schemas[0].Tables[0].ReplicationMethod = IncrementalReplication
+		Expect(tap.validate(context.Background(), nil, admissionv1.Update)).Should(MatchError(ContainSubstring("spec.mysql.schemas[0].tables[0].replication_key")))
+
+		target := &PipelinewiseTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "warehouse", Namespace: "default"},
+		}
+		Expect(target.validate(context.Background(), nil, admissionv1.Create)).Should(MatchError(ContainSubstring("spec: Required value: exactly one target must be configured")))
+		target.Spec.TargetSpec = postgresTarget
+		Expect(target.validate(context.Background(), nil, admissionv1.Create)).Should(Succeed())
+	})
+
+	Context("With the referenced taps and targets at hand", func() {
+		var (
+			testScheme *runtime.Scheme
+			reader     client.Reader
+		)
+		// admit sends the resource through its validating webhook backed by the reader
+		admit := func(operation admissionv1.Operation, obj referenceValidator, newObject func() referenceValidator) admission.Response {
+			raw, err := json.Marshal(obj)
+			Expect(err).ShouldNot(HaveOccurred())
+			request := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation}}
+			if operation == admissionv1.Delete {
+				request.OldObject = runtime.RawExtension{Raw: raw}
+			} else {
+				request.Object = runtime.RawExtension{Raw: raw}
+			}
+			decoder, err := admission.NewDecoder(testScheme)
+			Expect(err).ShouldNot(HaveOccurred())
+			handler := &validatingHandler{reader: reader, newObject: newObject}
+			Expect(handler.InjectDecoder(decoder)).Should(Succeed())
+			return handler.Handle(context.Background(), request)
+		}
+		newTap := func() referenceValidator { return &PipelinewiseTap{} }
+		newTarget := func() referenceValidator { return &PipelinewiseTarget{} }
+
+		BeforeEach(func() {
+			testScheme = runtime.NewScheme()
+			Expect(AddToScheme(testScheme)).Should(Succeed())
+			reader = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+				&PipelinewiseTap{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders-db", Namespace: "default"},
+					Spec:       PipelinewiseTapSpec{TapSpec: mysqlTap(fullTable)},
+				},
+				&PipelinewiseTarget{
+					ObjectMeta: metav1.ObjectMeta{Name: "warehouse", Namespace: "default"},
+					Spec:       PipelinewiseTargetSpec{TargetSpec: postgresTarget},
+				},
+				&PipelinewiseJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+					Spec: PipelinewiseJobSpec{
+						TapRef:    &corev1.LocalObjectReference{Name: "orders-db"},
+						TargetRef: &corev1.LocalObjectReference{Name: "warehouse"},
+					},
+				},
+			).Build()
+		})
+
+		It("Should reject deleting taps and targets referenced by jobs", func() {
+			tap := &PipelinewiseTap{ObjectMeta: metav1.ObjectMeta{Name: "orders-db", Namespace: "default"}}
+			response := admit(admissionv1.Delete, tap, newTap)
+			Expect(response.Allowed).Should(BeFalse())
+			Expect(response.Result.Reason).Should(Equal(metav1.StatusReasonForbidden))
+			Expect(response.Result.Message).Should(ContainSubstring("still referenced by PipelinewiseJob orders"))
+			target := &PipelinewiseTarget{ObjectMeta: metav1.ObjectMeta{Name: "warehouse", Namespace: "default"}}
+			Expect(admit(admissionv1.Delete, target, newTarget).Allowed).Should(BeFalse())
+
+			Expect(admit(admissionv1.Delete, &PipelinewiseTap{ObjectMeta: metav1.ObjectMeta{Name: "unused", Namespace: "default"}}, newTap).Allowed).Should(BeTrue())
+			Expect(admit(admissionv1.Delete, &PipelinewiseTarget{ObjectMeta: metav1.ObjectMeta{Name: "warehouse", Namespace: "other"}}, newTarget).Allowed).Should(BeTrue())
+
+			By("Validating created taps like before")
+			tap.Spec.TapSpec = mysqlTap(fullTable)
+			Expect(admit(admissionv1.Create, tap, newTap).Allowed).Should(BeTrue())
+			tap.Spec.TapSpec = TapSpec{}
+			response = admit(admissionv1.Create, tap, newTap)
+			Expect(response.Allowed).Should(BeFalse())
+			Expect(response.Result.Reason).Should(Equal(metav1.StatusReasonInvalid))
+		})
+
+		It("Should reject referenced taps and targets sharing an id with other ones", func() {
+			pwJob := &PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "validation", Namespace: "default"},
+				Spec: PipelinewiseJobSpec{
+					Schedule: "0 0 * * *",
+					Taps: []JobTapSpec{
+						{TapSpec: mysqlTap(fullTable)},
+						{TapRef: &corev1.LocalObjectReference{Name: "orders-db"}},
+					},
+					Targets: []JobTargetSpec{
+						{TargetRef: &corev1.LocalObjectReference{Name: "warehouse"}},
+						{TargetSpec: postgresTarget},
+					},
+				},
+			}
+			err := pwJob.validate(context.Background(), reader, admissionv1.Create)
+			Expect(apierrors.IsInvalid(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring(`spec.taps[1].tapRef: Duplicate value: "mysql-db"`))
+			Expect(err.Error()).Should(ContainSubstring(`spec.targets[1]: Duplicate value: "postgres-"`))
+
+			pwJob.Spec.Taps[1].TapRef.Name = "missing"
+			pwJob.Spec.Targets = pwJob.Spec.Targets[:1]
+			Expect(pwJob.validate(context.Background(), reader, admissionv1.Create)).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,59 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelinewiseTapSpec defines a tap shared by every PipelinewiseJob referencing it through `tapRef`
+type PipelinewiseTapSpec struct {
+	TapSpec `json:",inline"`
+}
+
+// PipelinewiseTapStatus defines the observed state of PipelinewiseTap
+type PipelinewiseTapStatus struct {
+	// ReferencedBy lists the jobs referencing the tap, deletion is blocked until none is left
+	ReferencedBy []string `json:"referencedBy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Referenced By",type=string,JSONPath=`.status.referencedBy`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PipelinewiseTap is the Schema for the pipelinewisetaps API
+type PipelinewiseTap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PipelinewiseTapSpec   `json:"spec,omitempty"`
+	Status PipelinewiseTapStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PipelinewiseTapList contains a list of PipelinewiseTap
+type PipelinewiseTapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelinewiseTap `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PipelinewiseTap{}, &PipelinewiseTapList{})
+}
@@ -0,0 +1,71 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// log is for logging in this package.
+var pipelinewisetaplog = logf.Log.WithName("pipelinewisetap-resource")
+
+// SetupWebhookWithManager registers the PipelinewiseTap webhook to the manager
+func (r *PipelinewiseTap) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	registerValidatingWebhook(mgr, "/validate-batch-pipelinewise-v1alpha1-pipelinewisetap", func() referenceValidator {
+		return &PipelinewiseTap{}
+	})
+	return nil
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-batch-pipelinewise-v1alpha1-pipelinewisetap,mutating=false,failurePolicy=fail,groups=batch.pipelinewise,resources=pipelinewisetaps,versions=v1alpha1,name=vpipelinewisetap.kb.io
+
+// validate checks created and updated taps. A tap referenced by jobs may not be deleted, the in-use finalizer holds
+// back deletions the webhook did not see
+func (r *PipelinewiseTap) validate(ctx context.Context, reader client.Reader, operation admissionv1.Operation) error {
+	pipelinewisetaplog.Info("validate", "operation", operation, "name", r.Name)
+
+	if operation == admissionv1.Delete {
+		return validateUnreferenced(ctx, reader, GroupVersion.WithResource("pipelinewisetaps").GroupResource(), r.Namespace, r.Name, func(pwJob *PipelinewiseJob) bool {
+			return pwJob.ReferencesTap(r.Name)
+		})
+	}
+	return r.validatePipelinewiseTap()
+}
+
+func (r *PipelinewiseTap) validatePipelinewiseTap() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if err := validateSingleConnector(r.Spec.TapSpec, specPath, "tap"); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		allErrs = append(allErrs, validateTap(tapSpecInfo(r.Spec.TapSpec), specPath)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("PipelinewiseTap").GroupKind(), r.Name, allErrs)
+}
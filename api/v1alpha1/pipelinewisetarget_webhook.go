@@ -0,0 +1,71 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// log is for logging in this package.
+var pipelinewisetargetlog = logf.Log.WithName("pipelinewisetarget-resource")
+
+// SetupWebhookWithManager registers the PipelinewiseTarget webhook to the manager
+func (r *PipelinewiseTarget) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	registerValidatingWebhook(mgr, "/validate-batch-pipelinewise-v1alpha1-pipelinewisetarget", func() referenceValidator {
+		return &PipelinewiseTarget{}
+	})
+	return nil
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-batch-pipelinewise-v1alpha1-pipelinewisetarget,mutating=false,failurePolicy=fail,groups=batch.pipelinewise,resources=pipelinewisetargets,versions=v1alpha1,name=vpipelinewisetarget.kb.io
+
+// validate checks created and updated targets. A target referenced by jobs may not be deleted, the in-use finalizer holds
+// back deletions the webhook did not see
+func (r *PipelinewiseTarget) validate(ctx context.Context, reader client.Reader, operation admissionv1.Operation) error {
+	pipelinewisetargetlog.Info("validate", "operation", operation, "name", r.Name)
+
+	if operation == admissionv1.Delete {
+		return validateUnreferenced(ctx, reader, GroupVersion.WithResource("pipelinewisetargets").GroupResource(), r.Namespace, r.Name, func(pwJob *PipelinewiseJob) bool {
+			return pwJob.ReferencesTarget(r.Name)
+		})
+	}
+	return r.validatePipelinewiseTarget()
+}
+
+func (r *PipelinewiseTarget) validatePipelinewiseTarget() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if err := validateSingleConnector(r.Spec.TargetSpec, specPath, "target"); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		allErrs = append(allErrs, validateTarget(targetSpecInfo(r.Spec.TargetSpec), specPath)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("PipelinewiseTarget").GroupKind(), r.Name, allErrs)
+}
@@ -21,12 +21,97 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertHandlersSpec) DeepCopyInto(out *AlertHandlersSpec) {
+	*out = *in
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackAlertHandlerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VictorOps != nil {
+		in, out := &in.VictorOps, &out.VictorOps
+		*out = new(VictorOpsAlertHandlerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertHandlersSpec.
+func (in *AlertHandlersSpec) DeepCopy() *AlertHandlersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertHandlersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerOverrideSpec) DeepCopyInto(out *ContainerOverrideSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerOverrideSpec.
+func (in *ContainerOverrideSpec) DeepCopy() *ContainerOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContinuousSpec) DeepCopyInto(out *ContinuousSpec) {
+	*out = *in
+	if in.PauseSeconds != nil {
+		in, out := &in.PauseSeconds, &out.PauseSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContinuousSpec.
+func (in *ContinuousSpec) DeepCopy() *ContinuousSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContinuousSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubTapConnectionSpec) DeepCopyInto(out *GithubTapConnectionSpec) {
 	*out = *in
+	if in.AccessTokenFrom != nil {
+		in, out := &in.AccessTokenFrom, &out.AccessTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubTapConnectionSpec.
@@ -49,7 +134,8 @@ func (in *GithubTapSpec) DeepCopyInto(out *GithubTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubTapSpec.
@@ -65,6 +151,21 @@ func (in *GithubTapSpec) DeepCopy() *GithubTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GoogleAnalyticsOauthCredentials) DeepCopyInto(out *GoogleAnalyticsOauthCredentials) {
 	*out = *in
+	if in.ClientSecretFrom != nil {
+		in, out := &in.ClientSecretFrom, &out.ClientSecretFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessTokenFrom != nil {
+		in, out := &in.AccessTokenFrom, &out.AccessTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshTokenFrom != nil {
+		in, out := &in.RefreshTokenFrom, &out.RefreshTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleAnalyticsOauthCredentials.
@@ -83,7 +184,7 @@ func (in *GoogleAnalyticsTapConnectionSpec) DeepCopyInto(out *GoogleAnalyticsTap
 	if in.OauthCredentials != nil {
 		in, out := &in.OauthCredentials, &out.OauthCredentials
 		*out = new(GoogleAnalyticsOauthCredentials)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -108,6 +209,7 @@ func (in *GoogleAnalyticsTapSpec) DeepCopyInto(out *GoogleAnalyticsTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleAnalyticsTapSpec.
@@ -123,6 +225,26 @@ func (in *GoogleAnalyticsTapSpec) DeepCopy() *GoogleAnalyticsTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JiraTapConnectionSpec) DeepCopyInto(out *JiraTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OauthClientSecretFrom != nil {
+		in, out := &in.OauthClientSecretFrom, &out.OauthClientSecretFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessTokenFrom != nil {
+		in, out := &in.AccessTokenFrom, &out.AccessTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshTokenFrom != nil {
+		in, out := &in.RefreshTokenFrom, &out.RefreshTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JiraTapConnectionSpec.
@@ -145,7 +267,8 @@ func (in *JiraTapSpec) DeepCopyInto(out *JiraTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JiraTapSpec.
@@ -158,6 +281,48 @@ func (in *JiraTapSpec) DeepCopy() *JiraTapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobTapSpec) DeepCopyInto(out *JobTapSpec) {
+	*out = *in
+	in.TapSpec.DeepCopyInto(&out.TapSpec)
+	if in.TapRef != nil {
+		in, out := &in.TapRef, &out.TapRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobTapSpec.
+func (in *JobTapSpec) DeepCopy() *JobTapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobTapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobTargetSpec) DeepCopyInto(out *JobTargetSpec) {
+	*out = *in
+	in.TargetSpec.DeepCopyInto(&out.TargetSpec)
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobTargetSpec.
+func (in *JobTargetSpec) DeepCopy() *JobTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTapConnectionSpec) DeepCopyInto(out *KafkaTapConnectionSpec) {
 	*out = *in
@@ -244,6 +409,7 @@ func (in *KafkaTapSpec) DeepCopyInto(out *KafkaTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTapSpec.
@@ -256,9 +422,50 @@ func (in *KafkaTapSpec) DeepCopy() *KafkaTapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MainConfigSpec) DeepCopyInto(out *MainConfigSpec) {
+	*out = *in
+	if in.AlertHandlers != nil {
+		in, out := &in.AlertHandlers, &out.AlertHandlers
+		*out = new(AlertHandlersSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MainConfigSpec.
+func (in *MainConfigSpec) DeepCopy() *MainConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MainConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManualRunStatus) DeepCopyInto(out *ManualRunStatus) {
+	*out = *in
+	in.TriggeredTime.DeepCopyInto(&out.TriggeredTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManualRunStatus.
+func (in *ManualRunStatus) DeepCopy() *ManualRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManualRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MixpanelTapConnectionSpec) DeepCopyInto(out *MixpanelTapConnectionSpec) {
 	*out = *in
+	if in.APISecretFrom != nil {
+		in, out := &in.APISecretFrom, &out.APISecretFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ExportEvents != nil {
 		in, out := &in.ExportEvents, &out.ExportEvents
 		*out = make([]string, len(*in))
@@ -287,6 +494,7 @@ func (in *MixpanelTapSpec) DeepCopyInto(out *MixpanelTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MixpanelTapSpec.
@@ -302,6 +510,11 @@ func (in *MixpanelTapSpec) DeepCopy() *MixpanelTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBTapConnectionSpec) DeepCopyInto(out *MongoDBTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.WriteBatchRows != nil {
 		in, out := &in.WriteBatchRows, &out.WriteBatchRows
 		*out = new(int)
@@ -330,6 +543,7 @@ func (in *MongoDBTapSpec) DeepCopyInto(out *MongoDBTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBTapSpec.
@@ -345,6 +559,11 @@ func (in *MongoDBTapSpec) DeepCopy() *MongoDBTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MySQLTapConnectionSpec) DeepCopyInto(out *MySQLTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SessionSQLs != nil {
 		in, out := &in.SessionSQLs, &out.SessionSQLs
 		*out = make([]string, len(*in))
@@ -373,6 +592,7 @@ func (in *MySQLTapSpec) DeepCopyInto(out *MySQLTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MySQLTapSpec.
@@ -385,9 +605,57 @@ func (in *MySQLTapSpec) DeepCopy() *MySQLTapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationReceiverSpec) DeepCopyInto(out *NotificationReceiverSpec) {
+	*out = *in
+	in.URLFrom.DeepCopyInto(&out.URLFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationReceiverSpec.
+func (in *NotificationReceiverSpec) DeepCopy() *NotificationReceiverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationReceiverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSpec) DeepCopyInto(out *NotificationSpec) {
+	*out = *in
+	if in.LogTailLines != nil {
+		in, out := &in.LogTailLines, &out.LogTailLines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]NotificationReceiverSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSpec.
+func (in *NotificationSpec) DeepCopy() *NotificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OracleTapConnectionSpec) DeepCopyInto(out *OracleTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OracleTapConnectionSpec.
@@ -410,7 +678,8 @@ func (in *OracleTapSpec) DeepCopyInto(out *OracleTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OracleTapSpec.
@@ -429,7 +698,7 @@ func (in *PipelinewiseJob) DeepCopyInto(out *PipelinewiseJob) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseJob.
@@ -490,6 +759,11 @@ func (in *PipelinewiseJobSpec) DeepCopyInto(out *PipelinewiseJobSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Continuous != nil {
+		in, out := &in.Continuous, &out.Continuous
+		*out = new(ContinuousSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Suspend != nil {
 		in, out := &in.Suspend, &out.Suspend
 		*out = new(bool)
@@ -505,13 +779,92 @@ func (in *PipelinewiseJobSpec) DeepCopyInto(out *PipelinewiseJobSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RunHistoryLimit != nil {
+		in, out := &in.RunHistoryLimit, &out.RunHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 	in.Tap.DeepCopyInto(&out.Tap)
 	in.Target.DeepCopyInto(&out.Target)
+	if in.TapRef != nil {
+		in, out := &in.TapRef, &out.TapRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Taps != nil {
+		in, out := &in.Taps, &out.Taps
+		*out = make([]JobTapSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]JobTargetSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Secret != nil {
 		in, out := &in.Secret, &out.Secret
 		*out = new(SecretSpec)
 		**out = **in
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(VolumeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ResyncSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MainConfig != nil {
+		in, out := &in.MainConfig, &out.MainConfig
+		*out = new(MainConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateEdit != nil {
+		in, out := &in.StateEdit, &out.StateEdit
+		*out = new(StateEditSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseJobSpec.
@@ -527,6 +880,48 @@ func (in *PipelinewiseJobSpec) DeepCopy() *PipelinewiseJobSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelinewiseJobStatus) DeepCopyInto(out *PipelinewiseJobStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastManualRun != nil {
+		in, out := &in.LastManualRun, &out.LastManualRun
+		*out = new(ManualRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastResync != nil {
+		in, out := &in.LastResync, &out.LastResync
+		*out = new(ResyncStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastStateEdit != nil {
+		in, out := &in.LastStateEdit, &out.LastStateEdit
+		*out = new(StateEditStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecentRuns != nil {
+		in, out := &in.RecentRuns, &out.RecentRuns
+		*out = make([]RunStatistics, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseJobStatus.
@@ -540,61 +935,355 @@ func (in *PipelinewiseJobStatus) DeepCopy() *PipelinewiseJobStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgreSQLTapConnectionSpec) DeepCopyInto(out *PostgreSQLTapConnectionSpec) {
+func (in *PipelinewiseTap) DeepCopyInto(out *PipelinewiseTap) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTapConnectionSpec.
-func (in *PostgreSQLTapConnectionSpec) DeepCopy() *PostgreSQLTapConnectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTap.
+func (in *PipelinewiseTap) DeepCopy() *PipelinewiseTap {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgreSQLTapConnectionSpec)
+	out := new(PipelinewiseTap)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelinewiseTap) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgreSQLTapSpec) DeepCopyInto(out *PostgreSQLTapSpec) {
+func (in *PipelinewiseTapList) DeepCopyInto(out *PipelinewiseTapList) {
 	*out = *in
-	if in.Schemas != nil {
-		in, out := &in.Schemas, &out.Schemas
-		*out = make([]TapSchemaSpec, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PipelinewiseTap, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTapSpec.
-func (in *PostgreSQLTapSpec) DeepCopy() *PostgreSQLTapSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTapList.
+func (in *PipelinewiseTapList) DeepCopy() *PipelinewiseTapList {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgreSQLTapSpec)
+	out := new(PipelinewiseTapList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelinewiseTapList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgreSQLTargetSpec) DeepCopyInto(out *PostgreSQLTargetSpec) {
+func (in *PipelinewiseTapSpec) DeepCopyInto(out *PipelinewiseTapSpec) {
 	*out = *in
+	in.TapSpec.DeepCopyInto(&out.TapSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTargetSpec.
-func (in *PostgreSQLTargetSpec) DeepCopy() *PostgreSQLTargetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTapSpec.
+func (in *PipelinewiseTapSpec) DeepCopy() *PipelinewiseTapSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgreSQLTargetSpec)
+	out := new(PipelinewiseTapSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedshiftTargetSpec) DeepCopyInto(out *RedshiftTargetSpec) {
+func (in *PipelinewiseTapStatus) DeepCopyInto(out *PipelinewiseTapStatus) {
 	*out = *in
+	if in.ReferencedBy != nil {
+		in, out := &in.ReferencedBy, &out.ReferencedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTapStatus.
+func (in *PipelinewiseTapStatus) DeepCopy() *PipelinewiseTapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinewiseTapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinewiseTarget) DeepCopyInto(out *PipelinewiseTarget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTarget.
+func (in *PipelinewiseTarget) DeepCopy() *PipelinewiseTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinewiseTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelinewiseTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinewiseTargetList) DeepCopyInto(out *PipelinewiseTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PipelinewiseTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTargetList.
+func (in *PipelinewiseTargetList) DeepCopy() *PipelinewiseTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinewiseTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelinewiseTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinewiseTargetSpec) DeepCopyInto(out *PipelinewiseTargetSpec) {
+	*out = *in
+	in.TargetSpec.DeepCopyInto(&out.TargetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTargetSpec.
+func (in *PipelinewiseTargetSpec) DeepCopy() *PipelinewiseTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinewiseTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinewiseTargetStatus) DeepCopyInto(out *PipelinewiseTargetStatus) {
+	*out = *in
+	if in.ReferencedBy != nil {
+		in, out := &in.ReferencedBy, &out.ReferencedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinewiseTargetStatus.
+func (in *PipelinewiseTargetStatus) DeepCopy() *PipelinewiseTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinewiseTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodTemplateSpec) DeepCopyInto(out *PodTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(ContainerOverrideSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ContainerOverrideSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Runner != nil {
+		in, out := &in.Runner, &out.Runner
+		*out = new(ContainerOverrideSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTemplateSpec.
+func (in *PodTemplateSpec) DeepCopy() *PodTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgreSQLTapConnectionSpec) DeepCopyInto(out *PostgreSQLTapConnectionSpec) {
+	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTapConnectionSpec.
+func (in *PostgreSQLTapConnectionSpec) DeepCopy() *PostgreSQLTapConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgreSQLTapConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgreSQLTapSpec) DeepCopyInto(out *PostgreSQLTapSpec) {
+	*out = *in
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]TapSchemaSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTapSpec.
+func (in *PostgreSQLTapSpec) DeepCopy() *PostgreSQLTapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgreSQLTapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgreSQLTargetSpec) DeepCopyInto(out *PostgreSQLTargetSpec) {
+	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgreSQLTargetSpec.
+func (in *PostgreSQLTargetSpec) DeepCopy() *PostgreSQLTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgreSQLTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedshiftTargetSpec) DeepCopyInto(out *RedshiftTargetSpec) {
+	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSAccessKeyIDFrom != nil {
+		in, out := &in.AWSAccessKeyIDFrom, &out.AWSAccessKeyIDFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSAccessSecretKeyFrom != nil {
+		in, out := &in.AWSAccessSecretKeyFrom, &out.AWSAccessSecretKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSessionTokenFrom != nil {
+		in, out := &in.AWSSessionTokenFrom, &out.AWSSessionTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedshiftTargetSpec.
@@ -607,6 +1296,87 @@ func (in *RedshiftTargetSpec) DeepCopy() *RedshiftTargetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResyncSpec) DeepCopyInto(out *ResyncSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResyncSpec.
+func (in *ResyncSpec) DeepCopy() *ResyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResyncStatus) DeepCopyInto(out *ResyncStatus) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResyncStatus.
+func (in *ResyncStatus) DeepCopy() *ResyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunStatistics) DeepCopyInto(out *RunStatistics) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]TableStatistics, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunStatistics.
+func (in *RunStatistics) DeepCopy() *RunStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(RunStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3CSVTableMappingSpec) DeepCopyInto(out *S3CSVTableMappingSpec) {
 	*out = *in
@@ -630,6 +1400,21 @@ func (in *S3CSVTableMappingSpec) DeepCopy() *S3CSVTableMappingSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3CSVTapConnectionSpec) DeepCopyInto(out *S3CSVTapConnectionSpec) {
 	*out = *in
+	if in.AWSAccessKeyIDFrom != nil {
+		in, out := &in.AWSAccessKeyIDFrom, &out.AWSAccessKeyIDFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSecretAccessKeyFrom != nil {
+		in, out := &in.AWSSecretAccessKeyFrom, &out.AWSSecretAccessKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSessionTokenFrom != nil {
+		in, out := &in.AWSSessionTokenFrom, &out.AWSSessionTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3CSVTapConnectionSpec.
@@ -674,17 +1459,8 @@ func (in *S3CSVTapSpec) DeepCopyInto(out *S3CSVTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
-	if in.BatchSizeRows != nil {
-		in, out := &in.BatchSizeRows, &out.BatchSizeRows
-		*out = new(int)
-		**out = **in
-	}
-	if in.StreamBufferSize != nil {
-		in, out := &in.StreamBufferSize, &out.StreamBufferSize
-		*out = new(int)
-		**out = **in
-	}
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3CSVTapSpec.
@@ -701,6 +1477,13 @@ func (in *S3CSVTapSpec) DeepCopy() *S3CSVTapSpec {
 func (in *S3CSVTapTableSpec) DeepCopyInto(out *S3CSVTapTableSpec) {
 	*out = *in
 	in.Mapping.DeepCopyInto(&out.Mapping)
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]TransformationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3CSVTapTableSpec.
@@ -716,6 +1499,26 @@ func (in *S3CSVTapTableSpec) DeepCopy() *S3CSVTapTableSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3CSVTargetSpec) DeepCopyInto(out *S3CSVTargetSpec) {
 	*out = *in
+	if in.AWSAccessKeyIDFrom != nil {
+		in, out := &in.AWSAccessKeyIDFrom, &out.AWSAccessKeyIDFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSecretAccessKeyFrom != nil {
+		in, out := &in.AWSSecretAccessKeyFrom, &out.AWSSecretAccessKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSessionTokenFrom != nil {
+		in, out := &in.AWSSessionTokenFrom, &out.AWSSessionTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EncryptionKeyFrom != nil {
+		in, out := &in.EncryptionKeyFrom, &out.EncryptionKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3CSVTargetSpec.
@@ -731,6 +1534,16 @@ func (in *S3CSVTargetSpec) DeepCopy() *S3CSVTargetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SalesforceTapConnectionSpec) DeepCopyInto(out *SalesforceTapConnectionSpec) {
 	*out = *in
+	if in.ClientSecretFrom != nil {
+		in, out := &in.ClientSecretFrom, &out.ClientSecretFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshTokenFrom != nil {
+		in, out := &in.RefreshTokenFrom, &out.RefreshTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SalesforceTapConnectionSpec.
@@ -753,7 +1566,8 @@ func (in *SalesforceTapSpec) DeepCopyInto(out *SalesforceTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SalesforceTapSpec.
@@ -784,6 +1598,11 @@ func (in *SecretSpec) DeepCopy() *SecretSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShopifyTapConnectionSpec) DeepCopyInto(out *ShopifyTapConnectionSpec) {
 	*out = *in
+	if in.APIKeyFrom != nil {
+		in, out := &in.APIKeyFrom, &out.APIKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShopifyTapConnectionSpec.
@@ -806,7 +1625,8 @@ func (in *ShopifyTapSpec) DeepCopyInto(out *ShopifyTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShopifyTapSpec.
@@ -819,9 +1639,34 @@ func (in *ShopifyTapSpec) DeepCopy() *ShopifyTapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackAlertHandlerSpec) DeepCopyInto(out *SlackAlertHandlerSpec) {
+	*out = *in
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackAlertHandlerSpec.
+func (in *SlackAlertHandlerSpec) DeepCopy() *SlackAlertHandlerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackAlertHandlerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SlackTapConnectionSpec) DeepCopyInto(out *SlackTapConnectionSpec) {
 	*out = *in
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Channels != nil {
 		in, out := &in.Channels, &out.Channels
 		*out = make([]string, len(*in))
@@ -850,6 +1695,7 @@ func (in *SlackTapSpec) DeepCopyInto(out *SlackTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackTapSpec.
@@ -865,6 +1711,11 @@ func (in *SlackTapSpec) DeepCopy() *SlackTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SnowflakeTapConnectionSpec) DeepCopyInto(out *SnowflakeTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnowflakeTapConnectionSpec.
@@ -887,7 +1738,8 @@ func (in *SnowflakeTapSpec) DeepCopyInto(out *SnowflakeTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnowflakeTapSpec.
@@ -903,6 +1755,31 @@ func (in *SnowflakeTapSpec) DeepCopy() *SnowflakeTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SnowflakeTargetSpec) DeepCopyInto(out *SnowflakeTargetSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSAccessKeyIDFrom != nil {
+		in, out := &in.AWSAccessKeyIDFrom, &out.AWSAccessKeyIDFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSecretAccessKeyFrom != nil {
+		in, out := &in.AWSSecretAccessKeyFrom, &out.AWSSecretAccessKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSessionTokenFrom != nil {
+		in, out := &in.AWSSessionTokenFrom, &out.AWSSessionTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientSideEncryptionMasterKeyFrom != nil {
+		in, out := &in.ClientSideEncryptionMasterKeyFrom, &out.ClientSideEncryptionMasterKeyFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnowflakeTargetSpec.
@@ -915,13 +1792,95 @@ func (in *SnowflakeTargetSpec) DeepCopy() *SnowflakeTargetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateEditSpec) DeepCopyInto(out *StateEditSpec) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]StreamBookmarkSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateEditSpec.
+func (in *StateEditSpec) DeepCopy() *StateEditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StateEditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateEditStatus) DeepCopyInto(out *StateEditStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateEditStatus.
+func (in *StateEditStatus) DeepCopy() *StateEditStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StateEditStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamBookmarkSpec) DeepCopyInto(out *StreamBookmarkSpec) {
+	*out = *in
+	if in.Bookmark != nil {
+		in, out := &in.Bookmark, &out.Bookmark
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamBookmarkSpec.
+func (in *StreamBookmarkSpec) DeepCopy() *StreamBookmarkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamBookmarkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TableStatistics) DeepCopyInto(out *TableStatistics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TableStatistics.
+func (in *TableStatistics) DeepCopy() *TableStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(TableStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TapSchemaSpec) DeepCopyInto(out *TapSchemaSpec) {
 	*out = *in
 	if in.Tables != nil {
 		in, out := &in.Tables, &out.Tables
 		*out = make([]TapTableSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -935,6 +1894,81 @@ func (in *TapSchemaSpec) DeepCopy() *TapSchemaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TapSettingsSpec) DeepCopyInto(out *TapSettingsSpec) {
+	*out = *in
+	if in.BatchSizeRows != nil {
+		in, out := &in.BatchSizeRows, &out.BatchSizeRows
+		*out = new(int)
+		**out = **in
+	}
+	if in.BatchWaitLimitSeconds != nil {
+		in, out := &in.BatchWaitLimitSeconds, &out.BatchWaitLimitSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.StreamBufferSize != nil {
+		in, out := &in.StreamBufferSize, &out.StreamBufferSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.HardDelete != nil {
+		in, out := &in.HardDelete, &out.HardDelete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AddMetadataColumns != nil {
+		in, out := &in.AddMetadataColumns, &out.AddMetadataColumns
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DataFlatteningMaxLevel != nil {
+		in, out := &in.DataFlatteningMaxLevel, &out.DataFlatteningMaxLevel
+		*out = new(int)
+		**out = **in
+	}
+	if in.FlushAllStreams != nil {
+		in, out := &in.FlushAllStreams, &out.FlushAllStreams
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int)
+		**out = **in
+	}
+	if in.SplitLargeFiles != nil {
+		in, out := &in.SplitLargeFiles, &out.SplitLargeFiles
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SplitFileChunkSizeMB != nil {
+		in, out := &in.SplitFileChunkSizeMB, &out.SplitFileChunkSizeMB
+		*out = new(int)
+		**out = **in
+	}
+	if in.SplitFileMaxChunks != nil {
+		in, out := &in.SplitFileMaxChunks, &out.SplitFileMaxChunks
+		*out = new(int)
+		**out = **in
+	}
+	if in.ValidateRecords != nil {
+		in, out := &in.ValidateRecords, &out.ValidateRecords
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TapSettingsSpec.
+func (in *TapSettingsSpec) DeepCopy() *TapSettingsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TapSettingsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TapSpec) DeepCopyInto(out *TapSpec) {
 	*out = *in
@@ -1038,6 +2072,13 @@ func (in *TapSpec) DeepCopy() *TapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TapTableSpec) DeepCopyInto(out *TapTableSpec) {
 	*out = *in
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]TransformationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TapTableSpec.
@@ -1056,22 +2097,22 @@ func (in *TargetSpec) DeepCopyInto(out *TargetSpec) {
 	if in.Redshift != nil {
 		in, out := &in.Redshift, &out.Redshift
 		*out = new(RedshiftTargetSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.PostgreSQL != nil {
 		in, out := &in.PostgreSQL, &out.PostgreSQL
 		*out = new(PostgreSQLTargetSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Snowflake != nil {
 		in, out := &in.Snowflake, &out.Snowflake
 		*out = new(SnowflakeTargetSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.S3CSV != nil {
 		in, out := &in.S3CSV, &out.S3CSV
 		*out = new(S3CSVTargetSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1085,9 +2126,56 @@ func (in *TargetSpec) DeepCopy() *TargetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationConditionSpec) DeepCopyInto(out *TransformationConditionSpec) {
+	*out = *in
+	if in.Equals != nil {
+		in, out := &in.Equals, &out.Equals
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationConditionSpec.
+func (in *TransformationConditionSpec) DeepCopy() *TransformationConditionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationConditionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationSpec) DeepCopyInto(out *TransformationSpec) {
+	*out = *in
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		*out = make([]TransformationConditionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationSpec.
+func (in *TransformationSpec) DeepCopy() *TransformationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TwilioTapConnectionSpec) DeepCopyInto(out *TwilioTapConnectionSpec) {
 	*out = *in
+	if in.AuthTokenFrom != nil {
+		in, out := &in.AuthTokenFrom, &out.AuthTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TwilioTapConnectionSpec.
@@ -1110,7 +2198,8 @@ func (in *TwilioTapSpec) DeepCopyInto(out *TwilioTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TwilioTapSpec.
@@ -1123,9 +2212,84 @@ func (in *TwilioTapSpec) DeepCopy() *TwilioTapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueFromSource) DeepCopyInto(out *ValueFromSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueFromSource.
+func (in *ValueFromSource) DeepCopy() *ValueFromSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueFromSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VictorOpsAlertHandlerSpec) DeepCopyInto(out *VictorOpsAlertHandlerSpec) {
+	*out = *in
+	if in.BaseURLFrom != nil {
+		in, out := &in.BaseURLFrom, &out.BaseURLFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VictorOpsAlertHandlerSpec.
+func (in *VictorOpsAlertHandlerSpec) DeepCopy() *VictorOpsAlertHandlerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VictorOpsAlertHandlerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSpec.
+func (in *VolumeSpec) DeepCopy() *VolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZendeskTapConnectionSpec) DeepCopyInto(out *ZendeskTapConnectionSpec) {
 	*out = *in
+	if in.AccessTokenFrom != nil {
+		in, out := &in.AccessTokenFrom, &out.AccessTokenFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RateLimit != nil {
 		in, out := &in.RateLimit, &out.RateLimit
 		*out = new(int)
@@ -1164,6 +2328,7 @@ func (in *ZendeskTapSpec) DeepCopyInto(out *ZendeskTapSpec) {
 		}
 	}
 	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZendeskTapSpec.
@@ -1179,6 +2344,11 @@ func (in *ZendeskTapSpec) DeepCopy() *ZendeskTapSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZuoraTapConnectionSpec) DeepCopyInto(out *ZuoraTapConnectionSpec) {
 	*out = *in
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(ValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuoraTapConnectionSpec.
@@ -1201,7 +2371,8 @@ func (in *ZuoraTapSpec) DeepCopyInto(out *ZuoraTapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
+	in.TapSettingsSpec.DeepCopyInto(&out.TapSettingsSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZuoraTapSpec.
@@ -0,0 +1,66 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewCache builds the cache of the manager. Secrets, Pods and Deployments are only cached while they carry the job name
+// label of the operator, so the operator does not hold every Secret and Pod of the cluster in memory. Secrets referenced
+// by jobs and the pods of runs are read from the API server instead
+func NewCache() cache.NewCacheFunc {
+	requirement, err := labels.NewRequirement(pwJobNameLabel, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+	labeled := labels.NewSelector().Add(*requirement)
+	return cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.Secret{}:     {Label: labeled},
+			&corev1.Pod{}:        {Label: labeled},
+			&appsv1.Deployment{}: {Label: labeled},
+		},
+	})
+}
+
+// apiReader returns the reader for objects the cache does not hold, the client itself when the reconciler was set up
+// without one
+func (r *PipelinewiseJobReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
+// getLabeled reads an object the operator labels with the job name. Objects created by earlier operator versions are
+// missing the label, so they are read from the API server until they are labeled
+func (r *PipelinewiseJobReconciler) getLabeled(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := r.Get(ctx, key, obj)
+	if errors.IsNotFound(err) {
+		return r.apiReader().Get(ctx, key, obj)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// inUseFinalizer holds back the deletion of a PipelinewiseTap or PipelinewiseTarget while jobs reference it
+const inUseFinalizer string = "pipelinewise.batch/in-use"
+
+// resolveConnectorRefs copies referenced taps and targets into the job spec, so they are rendered like inline ones.
+// The resolved spec must not be written back to the job
+func (r *PipelinewiseJobReconciler) resolveConnectorRefs(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob) error {
+	if pwJob.Spec.TapRef != nil {
+		tap, err := r.getReferencedTap(ctx, pwJob.Namespace, pwJob.Spec.TapRef.Name)
+		if err != nil {
+			return err
+		}
+		pwJob.Spec.Tap = tap
+	}
+	for nth := range pwJob.Spec.Taps {
+		if pwJob.Spec.Taps[nth].TapRef == nil {
+			continue
+		}
+		tap, err := r.getReferencedTap(ctx, pwJob.Namespace, pwJob.Spec.Taps[nth].TapRef.Name)
+		if err != nil {
+			return err
+		}
+		pwJob.Spec.Taps[nth].TapSpec = tap
+	}
+	if pwJob.Spec.TargetRef != nil {
+		target, err := r.getReferencedTarget(ctx, pwJob.Namespace, pwJob.Spec.TargetRef.Name)
+		if err != nil {
+			return err
+		}
+		pwJob.Spec.Target = target
+	}
+	for nth := range pwJob.Spec.Targets {
+		if pwJob.Spec.Targets[nth].TargetRef == nil {
+			continue
+		}
+		target, err := r.getReferencedTarget(ctx, pwJob.Namespace, pwJob.Spec.Targets[nth].TargetRef.Name)
+		if err != nil {
+			return err
+		}
+		pwJob.Spec.Targets[nth].TargetSpec = target
+	}
+	return nil
+}
+
+// getReferencedTap returns a copy of the tap of the named PipelinewiseTap
+func (r *PipelinewiseJobReconciler) getReferencedTap(ctx context.Context, namespace, name string) (batchv1alpha1.TapSpec, error) {
+	var tap batchv1alpha1.PipelinewiseTap
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: name}, &tap); err != nil {
+		if errors.IsNotFound(err) {
+			return batchv1alpha1.TapSpec{}, fmt.Errorf("PipelinewiseTap %v not found", name)
+		}
+		return batchv1alpha1.TapSpec{}, err
+	}
+	return *tap.Spec.TapSpec.DeepCopy(), nil
+}
+
+// getReferencedTarget returns a copy of the target of the named PipelinewiseTarget
+func (r *PipelinewiseJobReconciler) getReferencedTarget(ctx context.Context, namespace, name string) (batchv1alpha1.TargetSpec, error) {
+	var target batchv1alpha1.PipelinewiseTarget
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: name}, &target); err != nil {
+		if errors.IsNotFound(err) {
+			return batchv1alpha1.TargetSpec{}, fmt.Errorf("PipelinewiseTarget %v not found", name)
+		}
+		return batchv1alpha1.TargetSpec{}, err
+	}
+	return *target.Spec.TargetSpec.DeepCopy(), nil
+}
+
+// listReferencingJobs returns the sorted names of the jobs in the namespace matched by references
+func listReferencingJobs(ctx context.Context, c client.Client, namespace string, references func(*batchv1alpha1.PipelinewiseJob) bool) ([]string, error) {
+	var pwJobs batchv1alpha1.PipelinewiseJobList
+	if err := c.List(ctx, &pwJobs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for i := range pwJobs.Items {
+		if references(&pwJobs.Items[i]) {
+			names = append(names, pwJobs.Items[i].Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// syncInUseFinalizer adds the in-use finalizer to a referenced resource and removes it once the resource is no longer referenced
+func syncInUseFinalizer(ctx context.Context, c client.Client, obj client.Object, inUse bool) error {
+	finalizers := obj.GetFinalizers()
+	switch {
+	case inUse && !containsString(finalizers, inUseFinalizer) && obj.GetDeletionTimestamp().IsZero():
+		obj.SetFinalizers(append(finalizers, inUseFinalizer))
+	case !inUse && containsString(finalizers, inUseFinalizer):
+		obj.SetFinalizers(removeString(finalizers, inUseFinalizer))
+	default:
+		return nil
+	}
+	return c.Update(ctx, obj)
+}
+
+// jobsReferencing maps a changed PipelinewiseTap or PipelinewiseTarget to the jobs referencing it, so their configuration is re-rendered
+func (r *PipelinewiseJobReconciler) jobsReferencing(references func(*batchv1alpha1.PipelinewiseJob, string) bool) func(client.Object) []reconcile.Request {
+	return func(obj client.Object) []reconcile.Request {
+		names, err := listReferencingJobs(context.Background(), r.Client, obj.GetNamespace(), func(pwJob *batchv1alpha1.PipelinewiseJob) bool {
+			return references(pwJob, obj.GetName())
+		})
+		if err != nil {
+			r.Log.Error(err, "Failed to list jobs referencing resource", "name", obj.GetName())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, name := range names {
+			requests = append(requests, reconcile.Request{NamespacedName: ktypes.NamespacedName{Namespace: obj.GetNamespace(), Name: name}})
+		}
+		return requests
+	}
+}
+
+// pipelinewiseJobToTap maps a job to the PipelinewiseTaps it references, so references are counted again when the job changes
+func pipelinewiseJobToTap(obj client.Object) []reconcile.Request {
+	pwJob, ok := obj.(*batchv1alpha1.PipelinewiseJob)
+	if !ok {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, ref := range pwJob.TapRefs() {
+		requests = append(requests, reconcile.Request{NamespacedName: ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: ref.Name}})
+	}
+	return requests
+}
+
+// pipelinewiseJobToTarget maps a job to the PipelinewiseTargets it references, so references are counted again when the job changes
+func pipelinewiseJobToTarget(obj client.Object) []reconcile.Request {
+	pwJob, ok := obj.(*batchv1alpha1.PipelinewiseJob)
+	if !ok {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, ref := range pwJob.TargetRefs() {
+		requests = append(requests, reconcile.Request{NamespacedName: ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: ref.Name}})
+	}
+	return requests
+}
@@ -0,0 +1,183 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// continuousRunType labels the pods of the continuous executor
+	continuousRunType string = "continuous"
+	// configHashAnnotation restarts the continuous executor when the rendered configuration changes,
+	// since the configuration is only imported when the pod starts
+	configHashAnnotation string = "pipelinewise.batch/config-hash"
+	// continuousRunnerScript runs the tap in a loop. SIGTERM is forwarded to the running tap, so it stops
+	// gracefully and the state file is not left half written
+	continuousRunnerScript string = `trap 'kill -TERM $child 2>/dev/null; wait $child; exit 0' TERM INT
+while true; do
+  /app/entrypoint.sh "$@" & child=$!
+  wait $child
+  sleep %d & child=$!
+  wait $child
+done`
+)
+
+// getContinuousExecutor builds the Deployment running the executor pod of the CronJob template in a loop.
+// It never runs more than one pod and replaces it with the Recreate strategy, so only one pod uses the state volume at a time.
+// The Deployment is scaled to zero while scheduled runs are still using the state volume or the job is suspended
+func getContinuousExecutor(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, executorJob batchv1.CronJob, configData map[string]string, waiting bool) appsv1.Deployment {
+	replicas := int32(1)
+	if waiting || (pwJob.Spec.Suspend != nil && *pwJob.Spec.Suspend) {
+		replicas = 0
+	}
+
+	selector := map[string]string{
+		pwJobNameLabel: pwJob.Name,
+		runTypeLabel:   continuousRunType,
+	}
+
+	pod := *executorJob.Spec.JobTemplate.Spec.Template.DeepCopy()
+	pod.Labels = mergeStringMap(pod.Labels, selector)
+	pod.Annotations = mergeStringMap(pod.Annotations, map[string]string{
+		configHashAnnotation: configHash(configData),
+	})
+	pod.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	for nth := range pod.Spec.Containers {
+		if pod.Spec.Containers[nth].Name != "runner" {
+			continue
+		}
+		pod.Spec.Containers[nth].Command = []string{
+			"/bin/bash",
+			"-c",
+			fmt.Sprintf(continuousRunnerScript, batchv1alpha1.GetPauseSeconds(pwJob)),
+			"runner",
+		}
+	}
+
+	meta := identifierToMeta(identifier)
+	meta.Labels = map[string]string{pwJobNameLabel: pwJob.Name}
+	return appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: pod,
+		},
+	}
+}
+
+// reconcileContinuousExecutor creates or updates the Deployment of a continuous job
+func (r *PipelinewiseJobReconciler) reconcileContinuousExecutor(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, updatedExecutor *appsv1.Deployment) error {
+	if err := ctrl.SetControllerReference(pwJob, updatedExecutor, r.Scheme); err != nil {
+		return err
+	}
+
+	var executor appsv1.Deployment
+	if err := r.getLabeled(ctx, client.ObjectKeyFromObject(updatedExecutor), &executor); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return r.Create(ctx, updatedExecutor)
+	}
+
+	executor.Labels = mergeStringMap(executor.Labels, updatedExecutor.Labels)
+	executor.Spec = updatedExecutor.Spec
+	if err := ctrl.SetControllerReference(pwJob, &executor, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Update(ctx, &executor); err != nil {
+		return err
+	}
+	*updatedExecutor = executor
+	return nil
+}
+
+// deleteContinuousExecutor removes the Deployment of a continuous job and reports whether it still exists.
+// Foreground deletion keeps the Deployment around until its pod is gone
+func (r *PipelinewiseJobReconciler) deleteContinuousExecutor(ctx context.Context, identifier ktypes.NamespacedName) (bool, error) {
+	var executor appsv1.Deployment
+	if err := r.Get(ctx, identifier, &executor); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if executor.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, &executor, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// hasContinuousPods reports whether pods of the continuous executor are left, including terminating ones which the
+// Deployment status does not count anymore while they may still write the state volume
+func (r *PipelinewiseJobReconciler) hasContinuousPods(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob) (bool, error) {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(pwJob.Namespace), client.MatchingLabels{pwJobNameLabel: pwJob.Name, runTypeLabel: continuousRunType}); err != nil {
+		return false, err
+	}
+	return len(pods.Items) > 0, nil
+}
+
+// continuousPodToPipelinewiseJob maps a pod of a continuous executor to its job, so pending state edits and resyncs
+// start once the pod is gone
+func continuousPodToPipelinewiseJob(obj client.Object) []reconcile.Request {
+	if obj.GetLabels()[runTypeLabel] != continuousRunType {
+		return nil
+	}
+	return jobToPipelinewiseJob(obj)
+}
+
+// configHash returns a stable hash of the rendered configuration files
+func configHash(configData map[string]string) string {
+	keys := []string{}
+	for key := range configData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := fnv.New64a()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write([]byte(configData[key]))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
@@ -0,0 +1,196 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	kbatchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CronJobVersion defines the batch API version the executor CronJob is managed with
+type CronJobVersion string
+
+const (
+	// CronJobV1 manages the executor CronJob through batch/v1, served since Kubernetes 1.21
+	CronJobV1 CronJobVersion = "v1"
+	// CronJobV1beta1 manages the executor CronJob through batch/v1beta1 on clusters older than Kubernetes 1.21
+	CronJobV1beta1 CronJobVersion = "v1beta1"
+)
+
+// DetectCronJobVersion asks the API server whether batch/v1 serves CronJobs and falls back to batch/v1beta1 otherwise
+func DetectCronJobVersion(discoveryClient discovery.ServerResourcesInterface) (CronJobVersion, error) {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(batchv1.SchemeGroupVersion.String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return CronJobV1beta1, nil
+		}
+		return "", err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "cronjobs" {
+			return CronJobV1, nil
+		}
+	}
+	return CronJobV1beta1, nil
+}
+
+// legacyCronJob reports whether the executor CronJob is managed through batch/v1beta1
+func (r *PipelinewiseJobReconciler) legacyCronJob() bool {
+	return r.CronJobVersion == CronJobV1beta1
+}
+
+// cronJobObject returns an empty CronJob of the managed API version, used to watch and delete executor CronJobs
+func (r *PipelinewiseJobReconciler) cronJobObject() client.Object {
+	if r.legacyCronJob() {
+		return &kbatchv1beta1.CronJob{}
+	}
+	return &batchv1.CronJob{}
+}
+
+// getCronJob loads the executor CronJob, converted to batch/v1 when it is served as batch/v1beta1
+func (r *PipelinewiseJobReconciler) getCronJob(ctx context.Context, identifier ktypes.NamespacedName, cronJob *batchv1.CronJob) error {
+	if !r.legacyCronJob() {
+		return r.Get(ctx, identifier, cronJob)
+	}
+
+	var legacy kbatchv1beta1.CronJob
+	if err := r.Get(ctx, identifier, &legacy); err != nil {
+		return err
+	}
+	*cronJob = fromV1beta1CronJob(legacy)
+	return nil
+}
+
+// listCronJobs lists executor CronJobs, converted to batch/v1 when they are served as batch/v1beta1
+func (r *PipelinewiseJobReconciler) listCronJobs(ctx context.Context, namespace string, labels client.MatchingLabels) ([]batchv1.CronJob, error) {
+	if !r.legacyCronJob() {
+		var cronJobs batchv1.CronJobList
+		if err := r.List(ctx, &cronJobs, client.InNamespace(namespace), labels); err != nil {
+			return nil, err
+		}
+		return cronJobs.Items, nil
+	}
+
+	var legacy kbatchv1beta1.CronJobList
+	if err := r.List(ctx, &legacy, client.InNamespace(namespace), labels); err != nil {
+		return nil, err
+	}
+	cronJobs := []batchv1.CronJob{}
+	for _, cronJob := range legacy.Items {
+		cronJobs = append(cronJobs, fromV1beta1CronJob(cronJob))
+	}
+	return cronJobs, nil
+}
+
+// createCronJob creates the executor CronJob in the managed API version
+func (r *PipelinewiseJobReconciler) createCronJob(ctx context.Context, cronJob *batchv1.CronJob) error {
+	if !r.legacyCronJob() {
+		return r.Create(ctx, cronJob)
+	}
+
+	legacy := toV1beta1CronJob(*cronJob)
+	if err := r.Create(ctx, &legacy); err != nil {
+		return err
+	}
+	*cronJob = fromV1beta1CronJob(legacy)
+	return nil
+}
+
+// updateCronJob updates the executor CronJob in the managed API version
+func (r *PipelinewiseJobReconciler) updateCronJob(ctx context.Context, cronJob *batchv1.CronJob) error {
+	if !r.legacyCronJob() {
+		return r.Update(ctx, cronJob)
+	}
+
+	legacy := toV1beta1CronJob(*cronJob)
+	if err := r.Update(ctx, &legacy); err != nil {
+		return err
+	}
+	*cronJob = fromV1beta1CronJob(legacy)
+	return nil
+}
+
+// deleteCronJob removes the executor CronJob together with its runs and reports whether it still exists.
+// Foreground deletion keeps the CronJob around until the pods of its runs are gone
+func (r *PipelinewiseJobReconciler) deleteCronJob(ctx context.Context, identifier ktypes.NamespacedName) (bool, error) {
+	cronJob := r.cronJobObject()
+	if err := r.Get(ctx, identifier, cronJob); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if cronJob.GetDeletionTimestamp().IsZero() {
+		if err := r.Delete(ctx, cronJob, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// toV1beta1CronJob converts a batch/v1 CronJob to batch/v1beta1, both versions share the same fields
+func toV1beta1CronJob(cronJob batchv1.CronJob) kbatchv1beta1.CronJob {
+	return kbatchv1beta1.CronJob{
+		ObjectMeta: *cronJob.ObjectMeta.DeepCopy(),
+		Spec: kbatchv1beta1.CronJobSpec{
+			Schedule:                cronJob.Spec.Schedule,
+			StartingDeadlineSeconds: cronJob.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:       kbatchv1beta1.ConcurrencyPolicy(cronJob.Spec.ConcurrencyPolicy),
+			Suspend:                 cronJob.Spec.Suspend,
+			JobTemplate: kbatchv1beta1.JobTemplateSpec{
+				ObjectMeta: *cronJob.Spec.JobTemplate.ObjectMeta.DeepCopy(),
+				Spec:       *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+			},
+			SuccessfulJobsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+		},
+		Status: kbatchv1beta1.CronJobStatus{
+			Active:           cronJob.Status.Active,
+			LastScheduleTime: cronJob.Status.LastScheduleTime,
+		},
+	}
+}
+
+// fromV1beta1CronJob converts a batch/v1beta1 CronJob to batch/v1
+func fromV1beta1CronJob(cronJob kbatchv1beta1.CronJob) batchv1.CronJob {
+	return batchv1.CronJob{
+		ObjectMeta: *cronJob.ObjectMeta.DeepCopy(),
+		Spec: batchv1.CronJobSpec{
+			Schedule:                cronJob.Spec.Schedule,
+			StartingDeadlineSeconds: cronJob.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:       batchv1.ConcurrencyPolicy(cronJob.Spec.ConcurrencyPolicy),
+			Suspend:                 cronJob.Spec.Suspend,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: *cronJob.Spec.JobTemplate.ObjectMeta.DeepCopy(),
+				Spec:       *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+			},
+			SuccessfulJobsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+		},
+		Status: batchv1.CronJobStatus{
+			Active:           cronJob.Status.Active,
+			LastScheduleTime: cronJob.Status.LastScheduleTime,
+		},
+	}
+}
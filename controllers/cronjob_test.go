@@ -0,0 +1,116 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kbatchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Executor CronJob version", func() {
+	It("Should detect the CronJob version served by the API server", func() {
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(DetectCronJobVersion(discoveryClient)).Should(Equal(CronJobV1))
+
+		legacyDiscovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}}},
+				{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{{Name: "cronjobs"}}},
+			},
+		}}
+		Expect(DetectCronJobVersion(legacyDiscovery)).Should(Equal(CronJobV1beta1))
+	})
+
+	It("Should manage the executor as batch/v1beta1 CronJob on old clusters", func() {
+		ctx := context.Background()
+		// The suite manager only watches the default namespace, this one is reconciled by the legacy reconciler alone
+		const legacyNamespace = "legacy-cronjob"
+		jobName := "legacy-cronjob"
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: legacyNamespace}})).Should(Succeed())
+
+		legacyClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+		Expect(err).ShouldNot(HaveOccurred())
+		reconciler := &PipelinewiseJobReconciler{
+			Client:         legacyClient,
+			Log:            ctrl.Log.WithName("controllers").WithName("PipelinewiseJob"),
+			Scheme:         scheme.Scheme,
+			CronJobVersion: CronJobV1beta1,
+		}
+
+		pwJob := &batchv1alpha1.PipelinewiseJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      jobName,
+				Namespace: legacyNamespace,
+			},
+			Spec: batchv1alpha1.PipelinewiseJobSpec{
+				Schedule: "0 0 * * *",
+				Tap: batchv1alpha1.TapSpec{
+					MySQL: &batchv1alpha1.MySQLTapSpec{
+						Schemas: []batchv1alpha1.TapSchemaSpec{},
+						Connection: batchv1alpha1.MySQLTapConnectionSpec{
+							Host: "mysql",
+						},
+					},
+				},
+				Target: batchv1alpha1.TargetSpec{
+					PostgreSQL: &batchv1alpha1.PostgreSQLTargetSpec{
+						Host: "postgresql",
+					},
+				},
+			},
+		}
+		Expect(legacyClient.Create(ctx, pwJob)).Should(Succeed())
+
+		request := ctrl.Request{NamespacedName: types.NamespacedName{Name: jobName, Namespace: legacyNamespace}}
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		cronJobLookupKey := types.NamespacedName{Name: "pw-job-" + jobName, Namespace: legacyNamespace}
+		createdCronJob := &kbatchv1beta1.CronJob{}
+		Expect(legacyClient.Get(ctx, cronJobLookupKey, createdCronJob)).Should(Succeed())
+		Expect(createdCronJob.Spec.Schedule).Should(Equal("0 0 * * *"))
+		Expect(metav1.IsControlledBy(createdCronJob, pwJob)).Should(BeTrue())
+		Expect(createdCronJob.Spec.JobTemplate.Labels).Should(HaveKeyWithValue("pwjob-name", jobName))
+		Expect(createdCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).Should(ContainElement("run_tap"))
+
+		Expect(legacyClient.Get(ctx, request.NamespacedName, pwJob)).Should(Succeed())
+		pwJob.Spec.Schedule = "*/5 * * * *"
+		Expect(legacyClient.Update(ctx, pwJob)).Should(Succeed())
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(legacyClient.Get(ctx, cronJobLookupKey, createdCronJob)).Should(Succeed())
+		Expect(createdCronJob.Spec.Schedule).Should(Equal("*/5 * * * *"))
+		Expect(legacyClient.Get(ctx, request.NamespacedName, pwJob)).Should(Succeed())
+		Expect(meta.IsStatusConditionTrue(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled)).Should(BeTrue())
+	})
+})
@@ -0,0 +1,85 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// eventInterval suppresses an event repeating the reason and message of an event recently emitted on the same job,
+// so a fast requeue loop does not flood the job with events
+const eventInterval = 10 * time.Minute
+
+// emittedEvents holds the time every event was last emitted, keyed by job, reason and message
+var emittedEvents = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: map[string]time.Time{}}
+
+// allowEvent reports whether the event was not emitted within eventInterval and records it as emitted
+func allowEvent(key string, now time.Time) bool {
+	emittedEvents.Lock()
+	defer emittedEvents.Unlock()
+	for emittedKey, emittedTime := range emittedEvents.last {
+		if now.Sub(emittedTime) >= eventInterval {
+			delete(emittedEvents.last, emittedKey)
+		}
+	}
+	if _, ok := emittedEvents.last[key]; ok {
+		return false
+	}
+	emittedEvents.last[key] = now
+	return true
+}
+
+// event emits an event on the job unless the same event was emitted recently
+func (r *PipelinewiseJobReconciler) event(pwJob *batchv1alpha1.PipelinewiseJob, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	message := fmt.Sprintf(messageFmt, args...)
+	if !allowEvent(fmt.Sprintf("%v/%v/%v", pwJob.UID, reason, message), time.Now()) {
+		return
+	}
+	r.Recorder.Event(pwJob, eventType, reason, message)
+}
+
+// runEvents emits events for runs which started or finished since the status was last updated
+func (r *PipelinewiseJobReconciler) runEvents(pwJob *batchv1alpha1.PipelinewiseJob, previouslyActive []string, newRuns []finishedRun) {
+	for _, jobName := range pwJob.Status.Active {
+		if !containsString(previouslyActive, jobName) {
+			r.event(pwJob, corev1.EventTypeNormal, "RunStarted", "Job %v started", jobName)
+		}
+	}
+	for _, run := range newRuns {
+		if run.Succeeded {
+			r.event(pwJob, corev1.EventTypeNormal, "RunSucceeded", "Job %v succeeded after %vs", run.JobName, run.DurationSeconds)
+			continue
+		}
+		if run.ExitCode != nil {
+			r.event(pwJob, corev1.EventTypeWarning, "RunFailed", "Job %v failed after %vs with exit code %v", run.JobName, run.DurationSeconds, *run.ExitCode)
+		} else {
+			r.event(pwJob, corev1.EventTypeWarning, "RunFailed", "Job %v failed after %vs", run.JobName, run.DurationSeconds)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// mainConfigMapSetting selects the operator-level main configuration ConfigMap as `<namespace>/<name>`, or by name
+	// in the operator namespace
+	mainConfigMapSetting string = "PIPELINEWISE_MAIN_CONFIG"
+	// operatorNamespaceSetting holds the namespace the operator runs in, set through the downward API
+	operatorNamespaceSetting string = "POD_NAMESPACE"
+	// loggingConfEnv points pipelinewise to a custom logging configuration
+	loggingConfEnv string = "LOGGING_CONF_FILE"
+)
+
+// getMainConfigMap returns the operator-level main configuration ConfigMap, if the operator is configured with one.
+// A bare name selects the ConfigMap in the operator namespace
+func getMainConfigMap() (ktypes.NamespacedName, bool, error) {
+	setting := viper.GetString(mainConfigMapSetting)
+	if setting == "" {
+		return ktypes.NamespacedName{}, false, nil
+	}
+	namespace, name := viper.GetString(operatorNamespaceSetting), setting
+	if separator := strings.Index(setting, "/"); separator >= 0 {
+		namespace, name = setting[:separator], setting[separator+1:]
+	}
+	if namespace == "" || name == "" {
+		return ktypes.NamespacedName{}, false, fmt.Errorf("%v %q must be given as <namespace>/<name>, or as <name> with %v set to the operator namespace", mainConfigMapSetting, setting, operatorNamespaceSetting)
+	}
+	return ktypes.NamespacedName{Namespace: namespace, Name: name}, true, nil
+}
+
+// ValidateMainConfigSetting checks the operator-level main configuration setting, so a misconfigured operator fails
+// at startup instead of degrading every job without main configuration
+func ValidateMainConfigSetting() error {
+	_, _, err := getMainConfigMap()
+	return err
+}
+
+// getMainConfig returns the main configuration of the job, falling back to the operator-level ConfigMap.
+// Secrets referenced by the operator-level configuration are read from the job namespace like every other reference
+func (r *PipelinewiseJobReconciler) getMainConfig(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob) (*batchv1alpha1.MainConfigSpec, error) {
+	if pwJob.Spec.MainConfig != nil {
+		return pwJob.Spec.MainConfig, nil
+	}
+	identifier, ok, err := getMainConfigMap()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, identifier, &configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	mainConfig := &batchv1alpha1.MainConfigSpec{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[batchv1alpha1.MainConfigMapKey]), mainConfig); err != nil {
+		return nil, fmt.Errorf("Invalid main configuration in ConfigMap %v: %v", identifier, err)
+	}
+	return mainConfig, nil
+}
+
+// getLoggingEnv points pipelinewise to the rendered logging configuration, if there is one
+func getLoggingEnv(configData map[string]string) []corev1.EnvVar {
+	if _, ok := configData[batchv1alpha1.LoggingConfigFileName]; !ok {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  loggingConfEnv,
+			Value: fmt.Sprintf("/configurations/%v", batchv1alpha1.LoggingConfigFileName),
+		},
+	}
+}
+
+// mainConfigToPipelinewiseJobs re-renders every job using the operator-level main configuration once its ConfigMap changes
+func (r *PipelinewiseJobReconciler) mainConfigToPipelinewiseJobs(obj client.Object) []reconcile.Request {
+	identifier, ok, _ := getMainConfigMap()
+	if !ok || obj.GetNamespace() != identifier.Namespace || obj.GetName() != identifier.Name {
+		return nil
+	}
+
+	var pwJobs batchv1alpha1.PipelinewiseJobList
+	if err := r.List(context.Background(), &pwJobs); err != nil {
+		r.Log.Error(err, "Failed to list jobs using the main configuration", "configmap", identifier)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, pwJob := range pwJobs.Items {
+		if pwJob.Spec.MainConfig == nil {
+			requests = append(requests, reconcile.Request{NamespacedName: ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: pwJob.Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,64 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Main configuration setting", func() {
+	AfterEach(func() {
+		viper.Set(mainConfigMapSetting, "")
+		viper.Set(operatorNamespaceSetting, "")
+	})
+
+	It("Should select the ConfigMap given with its namespace", func() {
+		viper.Set(mainConfigMapSetting, "pipelinewise/main-config")
+		viper.Set(operatorNamespaceSetting, "operator")
+		identifier, ok, err := getMainConfigMap()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ok).Should(BeTrue())
+		Expect(identifier).Should(Equal(types.NamespacedName{Namespace: "pipelinewise", Name: "main-config"}))
+	})
+
+	It("Should select a ConfigMap given by name in the operator namespace", func() {
+		viper.Set(mainConfigMapSetting, "main-config")
+		viper.Set(operatorNamespaceSetting, "operator")
+		identifier, ok, err := getMainConfigMap()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ok).Should(BeTrue())
+		Expect(identifier).Should(Equal(types.NamespacedName{Namespace: "operator", Name: "main-config"}))
+		Expect(ValidateMainConfigSetting()).Should(Succeed())
+	})
+
+	It("Should reject a ConfigMap given by name while the operator namespace is unknown", func() {
+		viper.Set(mainConfigMapSetting, "main-config")
+		_, ok, err := getMainConfigMap()
+		Expect(err).Should(MatchError(ContainSubstring("<namespace>/<name>")))
+		Expect(ok).Should(BeFalse())
+		Expect(ValidateMainConfigSetting()).ShouldNot(Succeed())
+	})
+
+	It("Should not select a ConfigMap unless configured", func() {
+		_, ok, err := getMainConfigMap()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ok).Should(BeFalse())
+	})
+})
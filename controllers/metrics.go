@@ -0,0 +1,250 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// jobMetricLabels identify the PipelinewiseJob of every series
+var jobMetricLabels = []string{"namespace", "job", "tap_type", "target_type"}
+
+var (
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipelinewise_job_runs_total",
+		Help: "Number of finished runs by result",
+	}, append(jobMetricLabels, "result"))
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pipelinewise_job_last_success_timestamp_seconds",
+		Help: "Completion time of the last successful run as unix timestamp",
+	}, jobMetricLabels)
+	jobRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipelinewise_job_run_duration_seconds",
+		Help:    "Duration of finished runs by result",
+		Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+	}, append(jobMetricLabels, "result"))
+	jobTableRowsLoaded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pipelinewise_job_table_rows_loaded",
+		Help: "Rows loaded per table by the latest finished run of every pipeline",
+	}, append(jobMetricLabels, "tap", "target", "table"))
+	jobReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipelinewise_job_reconcile_errors_total",
+		Help: "Number of failed reconciliations by stage",
+	}, append(jobMetricLabels, "stage"))
+	jobSuspended = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pipelinewise_job_suspended",
+		Help: "Whether scheduled runs of the job are suspended or paused by the operator",
+	}, jobMetricLabels)
+)
+
+// reconcileStages groups the reasons of a degraded job into the stage which failed
+var reconcileStages = map[string]string{
+	"RenderFailed":     "config",
+	"ReferenceFailed":  "config",
+	"ConfigFailed":     "config",
+	"VolumeFailed":     "pvc",
+	"ListJobsFailed":   "runs",
+	"StatisticsFailed": "runs",
+	"CronJobFailed":    "cronjob",
+	"DeploymentFailed": "deployment",
+	"RunNowFailed":     "run-now",
+	"ResyncFailed":     "resync",
+	"StateEditFailed":  "state-edit",
+}
+
+func init() {
+	metrics.Registry.MustRegister(jobRuns, jobLastSuccess, jobRunDuration, jobTableRowsLoaded, jobReconcileErrors, jobSuspended)
+}
+
+// exportedJob remembers the series exported for a job, so they can be removed once the job is deleted or its labels change
+type exportedJob struct {
+	labels    prometheus.Labels
+	latestRun string
+	tables    map[tableSeries]bool
+	stages    map[string]bool
+}
+
+// tableSeries identifies the rows loaded into a table by a pipeline
+type tableSeries struct {
+	tap    string
+	target string
+	table  string
+}
+
+// labels extends the labels of the job with the pipeline and table
+func (t tableSeries) labels(labels prometheus.Labels) prometheus.Labels {
+	return withLabel(withLabel(withLabel(labels, "tap", t.tap), "target", t.target), "table", t.table)
+}
+
+// exportedJobs holds the series exported per job
+var exportedJobs = struct {
+	sync.Mutex
+	jobs map[ktypes.NamespacedName]*exportedJob
+}{jobs: map[ktypes.NamespacedName]*exportedJob{}}
+
+// getJobMetricLabels returns the labels shared by every series of the job
+func getJobMetricLabels(pwJob *batchv1alpha1.PipelinewiseJob) prometheus.Labels {
+	return prometheus.Labels{
+		"namespace":   pwJob.Namespace,
+		"job":         pwJob.Name,
+		"tap_type":    batchv1alpha1.GetTapConnectorID(pwJob),
+		"target_type": batchv1alpha1.GetTargetConnectorID(pwJob),
+	}
+}
+
+// withLabel copies the labels with one more label
+func withLabel(labels prometheus.Labels, name, value string) prometheus.Labels {
+	extended := prometheus.Labels{name: value}
+	for k, v := range labels {
+		extended[k] = v
+	}
+	return extended
+}
+
+// trackJob returns the exported series of the job, removing series exported with different label values before.
+// Without relabel the series keep the labels they were exported with, as the connector types of a job whose references
+// failed to resolve are unknown. The caller must hold the lock of exportedJobs
+func trackJob(pwJob *batchv1alpha1.PipelinewiseJob, relabel bool) *exportedJob {
+	key := ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: pwJob.Name}
+	labels := getJobMetricLabels(pwJob)
+	exported, ok := exportedJobs.jobs[key]
+	if ok && (!relabel || exported.labels["tap_type"] == labels["tap_type"] && exported.labels["target_type"] == labels["target_type"]) {
+		return exported
+	}
+	if ok {
+		deleteJobSeries(exported)
+	}
+	exported = &exportedJob{labels: labels, tables: map[tableSeries]bool{}, stages: map[string]bool{}}
+	exportedJobs.jobs[key] = exported
+	return exported
+}
+
+// recordJobMetrics exports the state of the job and the runs recorded during this reconciliation
+func recordJobMetrics(pwJob *batchv1alpha1.PipelinewiseJob, newRuns []finishedRun) {
+	exportedJobs.Lock()
+	defer exportedJobs.Unlock()
+	exported := trackJob(pwJob, true)
+
+	// The operator pauses runs as well, e.g. during manual runs, state edits and resyncs or while another CronJob of the job runs
+	suspended := 0.0
+	if scheduled := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled); scheduled != nil {
+		if scheduled.Status == metav1.ConditionFalse {
+			suspended = 1
+		}
+	} else if pwJob.Spec.Suspend != nil && *pwJob.Spec.Suspend {
+		suspended = 1
+	}
+	jobSuspended.With(exported.labels).Set(suspended)
+	if pwJob.Status.LastSuccessfulTime != nil {
+		jobLastSuccess.With(exported.labels).Set(float64(pwJob.Status.LastSuccessfulTime.Unix()))
+	}
+
+	for _, run := range newRuns {
+		result := "failed"
+		if run.Succeeded {
+			result = "succeeded"
+		}
+		jobRuns.With(withLabel(exported.labels, "result", result)).Inc()
+		jobRunDuration.With(withLabel(exported.labels, "result", result)).Observe(float64(run.DurationSeconds))
+	}
+
+	// Tables missing from the latest run of their pipeline loaded nothing
+	if len(pwJob.Status.RecentRuns) > 0 && pwJob.Status.RecentRuns[0].JobName != exported.latestRun {
+		exported.latestRun = pwJob.Status.RecentRuns[0].JobName
+		rows := getLatestTableRows(pwJob.Status.RecentRuns)
+		for series := range exported.tables {
+			if _, ok := rows[series]; !ok {
+				jobTableRowsLoaded.With(series.labels(exported.labels)).Set(0)
+			}
+		}
+		for series, loaded := range rows {
+			jobTableRowsLoaded.With(series.labels(exported.labels)).Set(float64(loaded))
+			exported.tables[series] = true
+		}
+	}
+}
+
+// getLatestTableRows returns the rows loaded per table by the latest run of every pipeline. A job runs its pipelines from
+// several CronJobs, so the latest run of the job may not cover every pipeline
+func getLatestTableRows(runs []batchv1alpha1.RunStatistics) map[tableSeries]int64 {
+	rows := map[tableSeries]int64{}
+	covered := map[tableSeries]bool{}
+	// Recent runs are sorted newest first
+	for _, run := range runs {
+		runPipelines := map[tableSeries]bool{}
+		for _, table := range run.Tables {
+			pipeline := tableSeries{tap: table.Tap, target: table.Target}
+			if covered[pipeline] {
+				continue
+			}
+			runPipelines[pipeline] = true
+			rows[tableSeries{tap: table.Tap, target: table.Target, table: table.Name}] += table.RowsLoaded
+		}
+		for pipeline := range runPipelines {
+			covered[pipeline] = true
+		}
+	}
+	return rows
+}
+
+// recordReconcileError counts a failed reconciliation of the job by the stage derived from the degraded reason
+func recordReconcileError(pwJob *batchv1alpha1.PipelinewiseJob, reason string) {
+	stage, ok := reconcileStages[reason]
+	if !ok {
+		stage = "other"
+	}
+
+	exportedJobs.Lock()
+	defer exportedJobs.Unlock()
+	exported := trackJob(pwJob, reason != "ReferenceFailed")
+	jobReconcileErrors.With(withLabel(exported.labels, "stage", stage)).Inc()
+	exported.stages[stage] = true
+}
+
+// forgetJobMetrics removes every series of a deleted job
+func forgetJobMetrics(identifier ktypes.NamespacedName) {
+	exportedJobs.Lock()
+	defer exportedJobs.Unlock()
+	if exported, ok := exportedJobs.jobs[identifier]; ok {
+		deleteJobSeries(exported)
+		delete(exportedJobs.jobs, identifier)
+	}
+}
+
+// deleteJobSeries removes the series exported for a job
+func deleteJobSeries(exported *exportedJob) {
+	jobSuspended.Delete(exported.labels)
+	jobLastSuccess.Delete(exported.labels)
+	for _, result := range []string{"succeeded", "failed"} {
+		jobRuns.Delete(withLabel(exported.labels, "result", result))
+		jobRunDuration.Delete(withLabel(exported.labels, "result", result))
+	}
+	for series := range exported.tables {
+		jobTableRowsLoaded.Delete(series.labels(exported.labels))
+	}
+	for stage := range exported.stages {
+		jobReconcileErrors.Delete(withLabel(exported.labels, "stage", stage))
+	}
+}
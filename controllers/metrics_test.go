@@ -0,0 +1,92 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Job metrics", func() {
+	var pwJob *batchv1alpha1.PipelinewiseJob
+
+	BeforeEach(func() {
+		pwJob = &batchv1alpha1.PipelinewiseJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metrics",
+				Namespace: "metrics",
+			},
+			Spec: batchv1alpha1.PipelinewiseJobSpec{
+				Tap: batchv1alpha1.TapSpec{
+					MySQL: &batchv1alpha1.MySQLTapSpec{
+						Connection: batchv1alpha1.MySQLTapConnectionSpec{Host: "mysql", DBName: "orders"},
+					},
+				},
+				Target: batchv1alpha1.TargetSpec{
+					PostgreSQL: &batchv1alpha1.PostgreSQLTargetSpec{Host: "postgres", Port: 5432},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		forgetJobMetrics(ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: pwJob.Name})
+	})
+
+	It("Should keep the run history of a job while its references fail to resolve", func() {
+		labels := getJobMetricLabels(pwJob)
+		Expect(labels["tap_type"]).ShouldNot(BeEmpty())
+		recordJobMetrics(pwJob, []finishedRun{{RunStatistics: batchv1alpha1.RunStatistics{JobName: "run-1", Succeeded: true, DurationSeconds: 60}}})
+
+		unresolved := pwJob.DeepCopy()
+		unresolved.Spec.Tap = batchv1alpha1.TapSpec{}
+		unresolved.Spec.TapRef = &corev1.LocalObjectReference{Name: "orders-db"}
+		recordReconcileError(unresolved, "ReferenceFailed")
+		Expect(testutil.ToFloat64(jobRuns.With(withLabel(labels, "result", "succeeded")))).Should(Equal(1.0))
+		Expect(testutil.ToFloat64(jobReconcileErrors.With(withLabel(labels, "stage", "config")))).Should(Equal(1.0))
+
+		recordJobMetrics(pwJob, nil)
+		Expect(testutil.ToFloat64(jobRuns.With(withLabel(labels, "result", "succeeded")))).Should(Equal(1.0))
+	})
+
+	It("Should export the rows loaded by the latest run of every pipeline", func() {
+		pwJob.Status.RecentRuns = []batchv1alpha1.RunStatistics{
+			{JobName: "run-2", Tables: []batchv1alpha1.TableStatistics{
+				{Name: "orders", Tap: "mysql-billing", Target: "warehouse", RowsLoaded: 5},
+			}},
+			{JobName: "run-1", Tables: []batchv1alpha1.TableStatistics{
+				{Name: "orders", Tap: "mysql-billing", Target: "warehouse", RowsLoaded: 10},
+				{Name: "invoices", Tap: "mysql-billing", Target: "warehouse", RowsLoaded: 3},
+				{Name: "orders", Tap: "mysql-crm", Target: "warehouse", RowsLoaded: 7},
+			}},
+		}
+		Expect(getLatestTableRows(pwJob.Status.RecentRuns)).Should(Equal(map[tableSeries]int64{
+			{tap: "mysql-billing", target: "warehouse", table: "orders"}: 5,
+			{tap: "mysql-crm", target: "warehouse", table: "orders"}:     7,
+		}))
+
+		recordJobMetrics(pwJob, nil)
+		labels := getJobMetricLabels(pwJob)
+		Expect(testutil.ToFloat64(jobTableRowsLoaded.With(tableSeries{tap: "mysql-billing", target: "warehouse", table: "orders"}.labels(labels)))).Should(Equal(5.0))
+		Expect(testutil.ToFloat64(jobTableRowsLoaded.With(tableSeries{tap: "mysql-crm", target: "warehouse", table: "orders"}.labels(labels)))).Should(Equal(7.0))
+	})
+})
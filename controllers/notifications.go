@@ -0,0 +1,341 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// notificationClient posts notifications, a slow webhook must not hold up the other receivers for long
+var notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+// notificationTimeout bounds the delivery of all pending notifications during a single reconciliation
+const notificationTimeout = time.Minute
+
+// notificationKind defines why a run is notified
+type notificationKind string
+
+const (
+	failureNotification  notificationKind = "failure"
+	recoveryNotification notificationKind = "recovery"
+	successNotification  notificationKind = "success"
+)
+
+// notification is the payload posted to generic webhooks, Slack and Teams receive it formatted as message
+type notification struct {
+	Kind            notificationKind `json:"kind"`
+	Namespace       string           `json:"namespace"`
+	Name            string           `json:"name"`
+	TapID           string           `json:"tapId"`
+	TargetID        string           `json:"targetId"`
+	JobName         string           `json:"jobName"`
+	RunType         string           `json:"runType"`
+	FailedContainer string           `json:"failedContainer,omitempty"`
+	ExitCode        *int32           `json:"exitCode,omitempty"`
+	DurationSeconds int64            `json:"durationSeconds"`
+	CompletionTime  *metav1.Time     `json:"completionTime,omitempty"`
+	LogTail         string           `json:"logTail,omitempty"`
+}
+
+// getNotifications returns the notifications of the job, falling back to the notification ConfigMap of its namespace
+func (r *PipelinewiseJobReconciler) getNotifications(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob) (*batchv1alpha1.NotificationSpec, error) {
+	if pwJob.Spec.Notifications != nil {
+		return pwJob.Spec.Notifications, nil
+	}
+
+	var configMap corev1.ConfigMap
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: batchv1alpha1.NotificationConfigMapName}, &configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	notifications := &batchv1alpha1.NotificationSpec{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[batchv1alpha1.NotificationConfigMapKey]), notifications); err != nil {
+		return nil, fmt.Errorf("Invalid notifications in ConfigMap %v: %v", configMap.Name, err)
+	}
+	return notifications, nil
+}
+
+// getNotificationKinds decides which runs with a pending notification are notified, by their index in the recent runs.
+// Failures are always notified, the first success after a failure as recovery and other successes only on request
+func getNotificationKinds(notifications *batchv1alpha1.NotificationSpec, runs []batchv1alpha1.RunStatistics) map[int]notificationKind {
+	kinds := map[int]notificationKind{}
+	// Recent runs are sorted newest first
+	lastSucceeded := true
+	for nth := len(runs) - 1; nth >= 0; nth-- {
+		run := runs[nth]
+		switch {
+		case !run.NotificationPending:
+		case !run.Succeeded:
+			kinds[nth] = failureNotification
+		case !lastSucceeded:
+			kinds[nth] = recoveryNotification
+		case notifications.OnSuccess:
+			kinds[nth] = successNotification
+		}
+		lastSucceeded = run.Succeeded
+	}
+	return kinds
+}
+
+// notifyRuns posts the recent runs with a pending notification to the receivers of the job, oldest first. A run is only
+// marked as notified in status once every receiver accepted it, so failed deliveries are retried with the next
+// reconciliation and receivers may see a run again. newRuns carries the details of the runs recorded during this
+// reconciliation, the details of earlier runs are read from their Jobs again
+func (r *PipelinewiseJobReconciler) notifyRuns(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, newRuns []finishedRun) error {
+	pending := false
+	for _, run := range pwJob.Status.RecentRuns {
+		pending = pending || run.NotificationPending
+	}
+	if !pending {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+	notifications, err := r.getNotifications(ctx, pwJob)
+	if err != nil {
+		r.event(pwJob, corev1.EventTypeWarning, "NotificationFailed", "%v", err.Error())
+		return err
+	}
+	details := map[string]finishedRun{}
+	for _, run := range newRuns {
+		details[run.JobName] = run
+	}
+
+	kinds := map[int]notificationKind{}
+	if notifications != nil {
+		kinds = getNotificationKinds(notifications, pwJob.Status.RecentRuns)
+	}
+	var notifyErr error
+	notified := false
+	for nth := len(pwJob.Status.RecentRuns) - 1; nth >= 0; nth-- {
+		recorded := &pwJob.Status.RecentRuns[nth]
+		if !recorded.NotificationPending {
+			continue
+		}
+		if kind, ok := kinds[nth]; ok {
+			run, ok := details[recorded.JobName]
+			if !ok {
+				if run, notifyErr = r.getRecordedRun(ctx, pwJob, *recorded); notifyErr != nil {
+					break
+				}
+			}
+			if notifyErr = r.notifyRun(ctx, pwJob, notifications, kind, run); notifyErr != nil {
+				break
+			}
+		}
+		recorded.NotificationPending = false
+		notified = true
+	}
+
+	if notified {
+		if err := r.Status().Update(ctx, pwJob); err != nil {
+			return err
+		}
+	}
+	return notifyErr
+}
+
+// getRecordedRun reads the details of a run recorded in status from its Job, which carries none once the Job is removed
+func (r *PipelinewiseJobReconciler) getRecordedRun(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, run batchv1alpha1.RunStatistics) (finishedRun, error) {
+	var job batchv1.Job
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: run.JobName}, &job); err != nil {
+		if errors.IsNotFound(err) {
+			return finishedRun{RunStatistics: run}, nil
+		}
+		return finishedRun{}, err
+	}
+	termination, err := r.getRunTermination(ctx, &job)
+	if err != nil {
+		return finishedRun{}, err
+	}
+	return getFinishedRun(&job, run, termination), nil
+}
+
+// notifyRun posts the run to every receiver of the job. Delivery failures are reported as events
+func (r *PipelinewiseJobReconciler) notifyRun(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, notifications *batchv1alpha1.NotificationSpec, kind notificationKind, run finishedRun) error {
+	tapID := run.TapID
+	if tapID == "" {
+		tapID = string(batchv1alpha1.GetTapID(pwJob))
+	}
+	targetID := run.TargetID
+	if targetID == "" {
+		targetID = string(batchv1alpha1.GetTargetID(pwJob))
+	}
+	message := notification{
+		Kind:            kind,
+		Namespace:       pwJob.Namespace,
+		Name:            pwJob.Name,
+		TapID:           tapID,
+		TargetID:        targetID,
+		JobName:         run.JobName,
+		RunType:         run.RunType,
+		FailedContainer: run.FailedContainer,
+		ExitCode:        run.ExitCode,
+		DurationSeconds: run.DurationSeconds,
+		CompletionTime:  run.CompletionTime,
+		LogTail:         tailLines(run.LogTail, int(batchv1alpha1.GetLogTailLines(notifications))),
+	}
+	var notifyErr error
+	for _, receiver := range notifications.Receivers {
+		if err := r.postNotification(ctx, pwJob.Namespace, receiver, message); err != nil {
+			r.Log.Error(err, "Failed to send notification", "pipelinewisejob", pwJob.Name, "receiver", receiver.Type)
+			r.event(pwJob, corev1.EventTypeWarning, "NotificationFailed", "Failed to notify %v receiver about job %v: %v", receiver.Type, run.JobName, err)
+			notifyErr = fmt.Errorf("Failed to notify %v receiver about job %v: %v", receiver.Type, run.JobName, err)
+		}
+	}
+	return notifyErr
+}
+
+// postNotification formats the notification for the receiver and posts it to the referenced webhook URL
+func (r *PipelinewiseJobReconciler) postNotification(ctx context.Context, namespace string, receiver batchv1alpha1.NotificationReceiverSpec, message notification) error {
+	if receiver.URLFrom.SecretKeyRef == nil {
+		return fmt.Errorf("Webhook URL must be referenced from a Secret")
+	}
+	url, err := r.getSecretValue(ctx, namespace, receiver.URLFrom.SecretKeyRef)
+	if err != nil {
+		return err
+	}
+
+	var payload interface{}
+	switch receiver.Type {
+	case batchv1alpha1.SlackNotificationReceiver:
+		payload = slackMessage(message)
+	case batchv1alpha1.TeamsNotificationReceiver:
+		payload = teamsMessage(message)
+	default:
+		payload = message
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(string(url)), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := notificationClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("Webhook responded with %v", response.Status)
+	}
+	return nil
+}
+
+// notificationTitle summarizes the notification in a single line
+func notificationTitle(message notification) string {
+	switch message.Kind {
+	case failureNotification:
+		return fmt.Sprintf("Pipelinewise job %v/%v failed", message.Namespace, message.Name)
+	case recoveryNotification:
+		return fmt.Sprintf("Pipelinewise job %v/%v recovered", message.Namespace, message.Name)
+	default:
+		return fmt.Sprintf("Pipelinewise job %v/%v succeeded", message.Namespace, message.Name)
+	}
+}
+
+// notificationFacts lists the details of the notified run as markdown lines
+func notificationFacts(message notification) []string {
+	facts := []string{
+		fmt.Sprintf("*Tap:* `%v` *Target:* `%v`", message.TapID, message.TargetID),
+		fmt.Sprintf("*Run:* `%v` (%v) after %vs", message.JobName, message.RunType, message.DurationSeconds),
+	}
+	if message.FailedContainer != "" {
+		failed := fmt.Sprintf("*Failed container:* `%v`", message.FailedContainer)
+		if message.ExitCode != nil && isRunnerContainer(message.FailedContainer) {
+			failed = fmt.Sprintf("%v with exit code %v", failed, *message.ExitCode)
+		}
+		facts = append(facts, failed)
+	}
+	return facts
+}
+
+// notificationColor returns the color of the message attachment
+func notificationColor(message notification) string {
+	if message.Kind == failureNotification {
+		return "#d7000b"
+	}
+	return "#2eb886"
+}
+
+// slackMessage formats the notification for Slack incoming webhooks
+func slackMessage(message notification) map[string]interface{} {
+	text := strings.Join(notificationFacts(message), "\n")
+	if message.LogTail != "" {
+		text = fmt.Sprintf("%v\n```%v```", text, message.LogTail)
+	}
+	return map[string]interface{}{
+		"text": notificationTitle(message),
+		"attachments": []map[string]interface{}{
+			{
+				"color":     notificationColor(message),
+				"text":      text,
+				"mrkdwn_in": []string{"text"},
+			},
+		},
+	}
+}
+
+// teamsMessage formats the notification as message card for Microsoft Teams incoming webhooks
+func teamsMessage(message notification) map[string]interface{} {
+	text := strings.ReplaceAll(strings.Join(notificationFacts(message), "\n\n"), "*", "**")
+	if message.LogTail != "" {
+		text = fmt.Sprintf("%v\n\n<pre>%v</pre>", text, html.EscapeString(message.LogTail))
+	}
+	return map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    notificationTitle(message),
+		"title":      notificationTitle(message),
+		"themeColor": strings.TrimPrefix(notificationColor(message), "#"),
+		"text":       text,
+	}
+}
+
+// tailLines returns the last lines of the output
+func tailLines(output string, lines int) string {
+	if lines <= 0 {
+		return ""
+	}
+	split := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(split) > lines {
+		split = split[len(split)-lines:]
+	}
+	return strings.Join(split, "\n")
+}
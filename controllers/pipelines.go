@@ -0,0 +1,269 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// tapCronJobLabel marks the CronJobs of taps, or taps loaded into a target, running on a schedule of their own
+	tapCronJobLabel string = "pipelinewise.batch/tap-cronjob"
+	// tapIDAnnotation records the tap run by the Jobs of a tap CronJob
+	tapIDAnnotation string = "pipelinewise.batch/tap-id"
+	// targetIDAnnotation records the target loaded by the Jobs of a tap CronJob
+	targetIDAnnotation string = "pipelinewise.batch/target-id"
+)
+
+// invalidContainerNameChars matches characters not allowed in container names
+var invalidContainerNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// pipelineStep pairs the import and runner container of a pipeline in the executor pod
+type pipelineStep struct {
+	importer corev1.Container
+	runner   corev1.Container
+}
+
+// isImportContainer reports whether the container imports the configuration of a pipeline
+func isImportContainer(name string) bool {
+	return name == "import" || strings.HasPrefix(name, "import-")
+}
+
+// isRunnerContainer reports whether the container runs a pipeline
+func isRunnerContainer(name string) bool {
+	return name == "runner" || strings.HasPrefix(name, "runner-")
+}
+
+// pipelineContainerNames returns the names of the import and runner container of the pipeline. An executor pod running
+// a single pipeline keeps the plain names, otherwise the names are suffixed with the tap id
+func pipelineContainerNames(pipelines []batchv1alpha1.Pipeline, pipeline batchv1alpha1.Pipeline) (string, string) {
+	if len(pipelines) == 1 {
+		return "import", "runner"
+	}
+	suffix := invalidContainerNameChars.ReplaceAllString(strings.ToLower(string(pipeline.TapID())), "-")
+	// Container names are limited to 63 characters
+	if len(suffix) > 56 {
+		suffix = suffix[:56]
+	}
+	suffix = strings.Trim(suffix, "-")
+	return "import-" + suffix, "runner-" + suffix
+}
+
+// tapConfigFileName returns the configuration file of the pipeline tap
+func tapConfigFileName(pipeline batchv1alpha1.Pipeline) string {
+	return fmt.Sprintf("tap_%v.yaml", pipeline.TapID())
+}
+
+// targetConfigFileName returns the configuration file of the pipeline target
+func targetConfigFileName(pipeline batchv1alpha1.Pipeline) string {
+	return fmt.Sprintf("target_%v.yaml", pipeline.TargetID())
+}
+
+// pipelineConfigMounts mounts only the configuration files of the pipeline, so `import` only discovers its own tap.
+// Jobs with a single pipeline mount the whole configuration directory
+func pipelineConfigMounts(pwJob *batchv1alpha1.PipelinewiseJob, pipeline batchv1alpha1.Pipeline, configData map[string]string) []corev1.VolumeMount {
+	if len(batchv1alpha1.GetPipelines(pwJob)) <= 1 {
+		return []corev1.VolumeMount{
+			{
+				Name:      "pipelinewise-configuration",
+				MountPath: "/configurations",
+			},
+		}
+	}
+
+	volumeMounts := []corev1.VolumeMount{}
+	for _, fileName := range []string{tapConfigFileName(pipeline), targetConfigFileName(pipeline), batchv1alpha1.MainConfigFileName, batchv1alpha1.LoggingConfigFileName} {
+		if _, ok := configData[fileName]; !ok {
+			continue
+		}
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "pipelinewise-configuration",
+			MountPath: fmt.Sprintf("/configurations/%v", fileName),
+			SubPath:   fileName,
+		})
+	}
+	return volumeMounts
+}
+
+// setPipelineSteps lays the steps out in the executor pod, so they run one after the other. Every container but the
+// last runner is an init container, a failing step stops the run
+func setPipelineSteps(podSpec *corev1.PodSpec, steps []pipelineStep) {
+	podSpec.InitContainers, podSpec.Containers = nil, nil
+	for nth, step := range steps {
+		podSpec.InitContainers = append(podSpec.InitContainers, step.importer)
+		if nth < len(steps)-1 {
+			podSpec.InitContainers = append(podSpec.InitContainers, step.runner)
+		} else {
+			podSpec.Containers = []corev1.Container{step.runner}
+		}
+	}
+}
+
+// getPipelineSteps returns the steps of the executor pod in run order
+func getPipelineSteps(podSpec corev1.PodSpec) []pipelineStep {
+	steps := []pipelineStep{}
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		switch {
+		case isImportContainer(container.Name):
+			steps = append(steps, pipelineStep{importer: container})
+		case isRunnerContainer(container.Name) && len(steps) > 0:
+			steps[len(steps)-1].runner = container
+		}
+	}
+	return steps
+}
+
+// getScheduledCronJobs builds the CronJobs running the pipelines of the job. Pipelines with a schedule of their own run
+// from their own CronJob, the job CronJob runs the other pipelines one after the other and is left out when there are none
+func getScheduledCronJobs(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, pipelines []batchv1alpha1.Pipeline, configVolume corev1.VolumeSource, configData map[string]string, pwVolume corev1.PersistentVolumeClaim, secretEnv []corev1.EnvVar) []batchv1.CronJob {
+	cronJobs := []batchv1.CronJob{}
+	unscheduled := []batchv1alpha1.Pipeline{}
+	for _, pipeline := range pipelines {
+		if pipeline.Schedule == "" {
+			unscheduled = append(unscheduled, pipeline)
+		}
+	}
+	if len(unscheduled) > 0 {
+		cronJobs = append(cronJobs, getExecutorJob(pwJob, identifier, unscheduled, configVolume, configData, pwVolume, secretEnv))
+	}
+
+	for _, pipeline := range pipelines {
+		if pipeline.Schedule == "" {
+			continue
+		}
+		cronJob := getExecutorJob(pwJob, tapCronJobIdentifier(identifier, pipeline), []batchv1alpha1.Pipeline{pipeline}, configVolume, configData, pwVolume, secretEnv)
+		cronJob.Labels = map[string]string{
+			pwJobNameLabel:  pwJob.Name,
+			tapCronJobLabel: "true",
+		}
+		cronJob.Spec.Schedule = pipeline.Schedule
+		cronJob.Spec.JobTemplate.Annotations = mergeStringMap(cronJob.Spec.JobTemplate.Annotations, map[string]string{
+			tapIDAnnotation:    string(pipeline.TapID()),
+			targetIDAnnotation: string(pipeline.TargetID()),
+		})
+		cronJobs = append(cronJobs, cronJob)
+	}
+	return cronJobs
+}
+
+// runningCronJobs returns the names of the CronJobs owning active scheduled runs of the job. Runs not owned by a
+// CronJob anymore are returned under an empty name, so they pause every CronJob
+func runningCronJobs(jobs []batchv1.Job) map[string]bool {
+	running := map[string]bool{}
+	for i := range jobs {
+		// One-off runs pause every CronJob on their own
+		if jobs[i].Labels[runTypeLabel] != "" {
+			continue
+		}
+		if finishedType, _ := getJobFinishedStatus(&jobs[i]); finishedType != "" {
+			continue
+		}
+		owner := metav1.GetControllerOf(&jobs[i])
+		if owner == nil || owner.Kind != "CronJob" {
+			running[""] = true
+			continue
+		}
+		running[owner.Name] = true
+	}
+	return running
+}
+
+// tapCronJobIdentifier names the CronJob of a pipeline running on a schedule of its own after the job CronJob and its tap id
+func tapCronJobIdentifier(identifier ktypes.NamespacedName, pipeline batchv1alpha1.Pipeline) ktypes.NamespacedName {
+	tapHash := fnv.New32a()
+	tapHash.Write([]byte(pipeline.TapID()))
+	return ktypes.NamespacedName{
+		Namespace: identifier.Namespace,
+		Name:      fmt.Sprintf("%v-%x", identifier.Name, tapHash.Sum32()),
+	}
+}
+
+// reconcileCronJob creates or updates an executor CronJob and returns it as stored
+func (r *PipelinewiseJobReconciler) reconcileCronJob(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, updatedCronJob batchv1.CronJob) (batchv1.CronJob, error) {
+	if err := ctrl.SetControllerReference(pwJob, &updatedCronJob, r.Scheme); err != nil {
+		return updatedCronJob, err
+	}
+
+	var cronJob batchv1.CronJob
+	if err := r.getCronJob(ctx, client.ObjectKeyFromObject(&updatedCronJob), &cronJob); err != nil {
+		if err := r.createCronJob(ctx, &updatedCronJob); err != nil {
+			return updatedCronJob, err
+		}
+		r.event(pwJob, corev1.EventTypeNormal, "CronJobCreated", "CronJob %v created with schedule %q", updatedCronJob.Name, updatedCronJob.Spec.Schedule)
+		return updatedCronJob, nil
+	}
+
+	cronJob.Labels = mergeStringMap(cronJob.Labels, updatedCronJob.Labels)
+	cronJob.Spec = updatedCronJob.Spec
+	if err := ctrl.SetControllerReference(pwJob, &cronJob, r.Scheme); err != nil {
+		return cronJob, err
+	}
+	resourceVersion := cronJob.ResourceVersion
+	if err := r.updateCronJob(ctx, &cronJob); err != nil {
+		return cronJob, err
+	}
+	// Updates without changes keep the resource version
+	if cronJob.ResourceVersion != resourceVersion {
+		r.event(pwJob, corev1.EventTypeNormal, "CronJobUpdated", "CronJob %v updated", cronJob.Name)
+	}
+	return cronJob, nil
+}
+
+// deleteStaleCronJobs removes the executor CronJobs of the job which are not kept and reports whether any of them still exists
+func (r *PipelinewiseJobReconciler) deleteStaleCronJobs(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, keep []batchv1.CronJob) (bool, error) {
+	kept := map[string]bool{}
+	for _, cronJob := range keep {
+		kept[cronJob.Name] = true
+	}
+
+	stale := []ktypes.NamespacedName{}
+	if !kept[identifier.Name] {
+		stale = append(stale, identifier)
+	}
+	tapCronJobs, err := r.listCronJobs(ctx, pwJob.Namespace, client.MatchingLabels{pwJobNameLabel: pwJob.Name, tapCronJobLabel: "true"})
+	if err != nil {
+		return false, err
+	}
+	for _, cronJob := range tapCronJobs {
+		if !kept[cronJob.Name] {
+			stale = append(stale, client.ObjectKeyFromObject(&cronJob))
+		}
+	}
+
+	active := false
+	for _, cronJobIdentifier := range stale {
+		exists, err := r.deleteCronJob(ctx, cronJobIdentifier)
+		if err != nil {
+			return false, err
+		}
+		active = active || exists
+	}
+	return active, nil
+}
@@ -19,20 +19,30 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
-	kbatchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	kresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
 )
@@ -41,23 +51,40 @@ import (
 type ExternalResourceID string
 
 const (
-	// ConfigMapExternalResourceID defines config map dependency ID
+	// ConfigMapExternalResourceID defines the rendered configuration dependency ID, stored in a Secret or a ConfigMap
 	ConfigMapExternalResourceID ExternalResourceID = "config"
 	// VolumeExternalResourceID defines volume dependency ID
 	VolumeExternalResourceID ExternalResourceID = "volume"
 	// JobMapExternalResourceID defines job dependency ID
 	JobMapExternalResourceID ExternalResourceID = "job"
-	// ConfigScriptExternalResourceID defines config map scripts dependency ID
-	ConfigScriptExternalResourceID ExternalResourceID = "config-script"
-	configModResourceName          string             = "pw-config-script"
-	scriptFileName                 string             = "configuration-mod.sh"
+	// pwJobNameLabel labels every Job spawned for a PipelinewiseJob with its name
+	pwJobNameLabel string = "pwjob-name"
+	// runTypeLabel marks Jobs created by the operator outside of the CronJob schedule
+	runTypeLabel string = "pipelinewise.batch/run-type"
+	// manualRunType labels Jobs triggered through the run-now annotation
+	manualRunType string = "manual"
+	// legacyFinalizerID was added to every PipelinewiseJob before generated resources carried owner references
+	legacyFinalizerID string = "pipelinewise"
+	// legacyConfigScriptName is the ConfigMap earlier operator versions shared between the jobs of a namespace to rewrite
+	// the rendered configuration with legacyConfigScriptKey
+	legacyConfigScriptName string = "pw-config-script"
+	legacyConfigScriptKey  string = "configuration-mod.sh"
 )
 
+// invalidEnvNameChars matches characters not allowed in environment variable names of referenced secrets
+var invalidEnvNameChars = regexp.MustCompile("[^A-Z0-9_]")
+
 // PipelinewiseJobReconciler reconciles a PipelinewiseJob object
 type PipelinewiseJobReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Recorder emits events on the PipelinewiseJob for tenants without access to the operator logs
+	Recorder record.EventRecorder
+	// CronJobVersion selects the batch API version of the executor CronJob, batch/v1 unless set to CronJobV1beta1
+	CronJobVersion CronJobVersion
+	// APIReader reads the objects left out of the cache built by NewCache
+	APIReader client.Reader
 }
 
 // Reconcile defines all operator flows to reconcile custom resources action
@@ -65,8 +92,13 @@ type PipelinewiseJobReconciler struct {
 // +kubebuilder:rbac:groups=batch.pipelinewise,resources=pipelinewisejobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;delete;deletecollection
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 func (r *PipelinewiseJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("pipelinewisejob", req.NamespacedName)
 
@@ -74,30 +106,25 @@ func (r *PipelinewiseJobReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	var pipelinewiseJob batchv1alpha1.PipelinewiseJob
 	if err := r.Get(ctx, req.NamespacedName, &pipelinewiseJob); err != nil {
 		if errors.IsNotFound(err) {
+			forgetJobMetrics(req.NamespacedName)
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 		return ctrl.Result{}, err
 	}
 
-	// Setup Finalizer
-	finalizerID := "pipelinewise"
-
-	if pipelinewiseJob.DeletionTimestamp.IsZero() {
-		if !containsString(pipelinewiseJob.ObjectMeta.Finalizers, finalizerID) {
-			pipelinewiseJob.ObjectMeta.Finalizers = append(pipelinewiseJob.ObjectMeta.Finalizers, finalizerID)
-			if err := r.Update(ctx, &pipelinewiseJob); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-	} else {
+	// Generated resources are owned by the PipelinewiseJob and garbage collected by Kubernetes.
+	// The finalizer is only honored for jobs created by earlier operator versions whose resources
+	// may not carry owner references yet.
+	if !pipelinewiseJob.DeletionTimestamp.IsZero() {
 		// Deletion flow
-		if containsString(pipelinewiseJob.ObjectMeta.Finalizers, finalizerID) {
-			if err := r.deleteExternalResources(&pipelinewiseJob); err != nil {
+		forgetJobMetrics(req.NamespacedName)
+		if containsString(pipelinewiseJob.ObjectMeta.Finalizers, legacyFinalizerID) {
+			if err := r.deleteExternalResources(ctx, &pipelinewiseJob); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			// remove our finalizer from the list and update it.
-			pipelinewiseJob.ObjectMeta.Finalizers = removeString(pipelinewiseJob.ObjectMeta.Finalizers, finalizerID)
+			pipelinewiseJob.ObjectMeta.Finalizers = removeString(pipelinewiseJob.ObjectMeta.Finalizers, legacyFinalizerID)
 			if err := r.Update(ctx, &pipelinewiseJob); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -107,141 +134,493 @@ func (r *PipelinewiseJobReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, nil
 	}
 
+	currentStatus := pipelinewiseJob.Status.DeepCopy()
 	identifiers := resourcesIdentifier(&pipelinewiseJob)
 
-	pwConfigScriptID := identifiers[ConfigScriptExternalResourceID]
-	var pwConfigScript corev1.ConfigMap
-	if err := r.Get(ctx, pwConfigScriptID, &pwConfigScript); err != nil {
-		pwConfigScript = getConfigScript(pwConfigScriptID)
-		err = r.Create(ctx, &pwConfigScript)
-		if err != nil {
-			log.Error(err, "Failed to create pipelinewise configuration")
-			return ctrl.Result{}, err
-		}
+	// Referenced taps and targets are rendered like inline ones, the spec keeps the references when the job is updated
+	unresolvedSpec := pipelinewiseJob.Spec.DeepCopy()
+	if err := r.resolveConnectorRefs(ctx, &pipelinewiseJob); err != nil {
+		setCondition(&pipelinewiseJob, batchv1alpha1.ConditionConfigRendered, metav1.ConditionFalse, "ReferenceFailed", err.Error())
+		return r.degraded(ctx, &pipelinewiseJob, "ReferenceFailed", err)
 	}
 
 	pwConfigID := identifiers[ConfigMapExternalResourceID]
-	var pwConfig corev1.ConfigMap
-	updatedPWConfig, err := r.getConfig(&pipelinewiseJob, pwConfigID)
+	configData, secretEnv, err := r.getConfig(ctx, &pipelinewiseJob)
 	if err != nil {
-		return ctrl.Result{}, err
+		setCondition(&pipelinewiseJob, batchv1alpha1.ConditionConfigRendered, metav1.ConditionFalse, "RenderFailed", err.Error())
+		return r.degraded(ctx, &pipelinewiseJob, "RenderFailed", err)
+	}
+	configVolume, err := r.reconcileConfig(ctx, &pipelinewiseJob, pwConfigID, configData)
+	if err != nil {
+		log.Error(err, "Failed to store pipelinewise configuration")
+		return r.degraded(ctx, &pipelinewiseJob, "ConfigFailed", err)
+	}
+	if err := r.deleteLegacyConfigScript(ctx, pipelinewiseJob.Namespace); err != nil {
+		log.Error(err, "Failed to remove legacy configuration script")
+		return r.degraded(ctx, &pipelinewiseJob, "ConfigFailed", err)
+	}
+	setCondition(&pipelinewiseJob, batchv1alpha1.ConditionConfigRendered, metav1.ConditionTrue, "Rendered", fmt.Sprintf("Configuration rendered into %v %v", batchv1alpha1.GetConfigStorage(&pipelinewiseJob), pwConfigID.Name))
+
+	// Create PVC
+	pwVolume, err := r.reconcileVolume(ctx, &pipelinewiseJob, identifiers[VolumeExternalResourceID])
+	if err != nil {
+		log.Error(err, "Failed to reconcile PVC")
+		return r.degraded(ctx, &pipelinewiseJob, "VolumeFailed", err)
 	}
-	if err := r.Get(ctx, pwConfigID, &pwConfig); err == nil {
-		// Update the content from the CRD
-		// Create new configMap
-		pwConfig.Data = updatedPWConfig.Data
-		err = r.Update(ctx, &pwConfig)
+
+	// Collect runs spawned for this job
+	var childJobs batchv1.JobList
+	if err := r.List(ctx, &childJobs, client.InNamespace(pipelinewiseJob.Namespace), client.MatchingLabels{pwJobNameLabel: pipelinewiseJob.Name}); err != nil {
+		log.Error(err, "Failed to list executor Jobs")
+		return r.degraded(ctx, &pipelinewiseJob, "ListJobsFailed", err)
+	}
+
+	jobIdentifier := identifiers[JobMapExternalResourceID]
+	// One-off runs and the continuous executor run every pipeline of the job one after the other
+	pipelines := batchv1alpha1.GetPipelines(&pipelinewiseJob)
+	updatedExecutorJob := getExecutorJob(&pipelinewiseJob, jobIdentifier, pipelines, configVolume, configData, pwVolume, secretEnv)
+	runNowToken, runNowRequested := pipelinewiseJob.Annotations[batchv1alpha1.RunNowAnnotation]
+	runNowConsumed := runNowRequested && pipelinewiseJob.Status.LastManualRun != nil && pipelinewiseJob.Status.LastManualRun.Token == runNowToken
+	runNowSkipped := false
+	registerStateEdit(&pipelinewiseJob)
+	registerResync(&pipelinewiseJob)
+	stateEditPending := isStateEditPending(&pipelinewiseJob)
+	resyncPending := isResyncPending(&pipelinewiseJob)
+
+	if batchv1alpha1.GetExecutionMode(&pipelinewiseJob) == batchv1alpha1.ContinuousExecutionMode {
+		// The continuous executor only starts once the scheduled runs left the state volume
+		cronJobActive, err := r.deleteStaleCronJobs(ctx, &pipelinewiseJob, jobIdentifier, nil)
 		if err != nil {
-			log.Error(err, "Failed to update pipelinewise configuration")
-			return ctrl.Result{}, err
+			log.Error(err, "Failed to remove executor CronJob")
+			return r.degraded(ctx, &pipelinewiseJob, "CronJobFailed", err)
+		}
+		waiting := cronJobActive || stateEditPending || resyncPending || hasActiveRun(childJobs.Items, "")
+		continuousExecutor := getContinuousExecutor(&pipelinewiseJob, jobIdentifier, updatedExecutorJob, configData, waiting)
+		if err := r.reconcileContinuousExecutor(ctx, &pipelinewiseJob, &continuousExecutor); err != nil {
+			log.Error(err, "Failed to reconcile continuous executor")
+			return r.degraded(ctx, &pipelinewiseJob, "DeploymentFailed", err)
+		}
+		// State edits and resyncs start once the continuous executor is scaled down and its pod is gone, state edits go first
+		idle := !cronJobActive && !hasActiveRun(childJobs.Items, "") && continuousExecutor.Status.Replicas == 0
+		if idle && (stateEditPending || resyncPending) {
+			podsLeft, err := r.hasContinuousPods(ctx, &pipelinewiseJob)
+			if err != nil {
+				log.Error(err, "Failed to list continuous executor pods")
+				return r.degraded(ctx, &pipelinewiseJob, "DeploymentFailed", err)
+			}
+			idle = !podsLeft
+		}
+		if stateEditPending && idle {
+			stateEditRun, err := r.startStateEdit(ctx, &pipelinewiseJob, updatedExecutorJob)
+			if err != nil {
+				log.Error(err, "Failed to create state edit Job")
+				return r.degraded(ctx, &pipelinewiseJob, "StateEditFailed", err)
+			}
+			childJobs.Items = append(childJobs.Items, *stateEditRun)
+		} else if resyncPending && idle {
+			resyncRun, err := r.startResync(ctx, &pipelinewiseJob, updatedExecutorJob)
+			if err != nil {
+				log.Error(err, "Failed to create resync Job")
+				return r.degraded(ctx, &pipelinewiseJob, "ResyncFailed", err)
+			}
+			childJobs.Items = append(childJobs.Items, *resyncRun)
+		}
+		// Continuous jobs have no manual runs, the token is consumed anyway so it neither lingers on the job nor fires
+		// once the job is scheduled again
+		if runNowRequested && !runNowConsumed {
+			log.Info("Skipping manual run of a continuously running job", "token", runNowToken)
+			pipelinewiseJob.Status.LastManualRun = &batchv1alpha1.ManualRunStatus{
+				Token:         runNowToken,
+				Skipped:       true,
+				TriggeredTime: metav1.Now(),
+			}
+			runNowConsumed, runNowSkipped = true, true
+		}
+
+		switch {
+		case stateEditPending || hasActiveRun(childJobs.Items, stateEditRunType):
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "StateEditActive", fmt.Sprintf("Deployment %v is paused while the replication state is edited", continuousExecutor.Name))
+		case resyncPending || hasActiveRun(childJobs.Items, resyncRunType):
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "ResyncActive", fmt.Sprintf("Deployment %v is paused while tables are resynced", continuousExecutor.Name))
+		case waiting:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "WaitingForRuns", fmt.Sprintf("Deployment %v waits until scheduled runs finish", continuousExecutor.Name))
+		case pipelinewiseJob.Spec.Suspend != nil && *pipelinewiseJob.Spec.Suspend:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "Suspended", fmt.Sprintf("Deployment %v is suspended", continuousExecutor.Name))
+		default:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionTrue, "Running", fmt.Sprintf("Deployment %v runs continuously", continuousExecutor.Name))
 		}
 	} else {
-		err = r.Create(ctx, &updatedPWConfig)
+		// Scheduled runs are paused until the continuous executor left the state volume
+		continuousActive, err := r.deleteContinuousExecutor(ctx, jobIdentifier)
 		if err != nil {
-			log.Error(err, "Failed to create pipelinewise configuration")
-			return ctrl.Result{}, err
+			log.Error(err, "Failed to remove continuous executor")
+			return r.degraded(ctx, &pipelinewiseJob, "DeploymentFailed", err)
 		}
-	}
 
-	// Create PVC
-	var pwVolume corev1.PersistentVolumeClaim
-	volumeIdentifier := identifiers[VolumeExternalResourceID]
-	if err := r.Get(ctx, volumeIdentifier, &pwVolume); err != nil {
-		pwVolume := defaultVolume(volumeIdentifier)
-		err = r.Create(ctx, &pwVolume)
-		if err != nil {
-			log.Error(err, "Failed to create PVC")
-			return ctrl.Result{}, err
+		// A run-now token starts a manual run once no other run uses the state volume.
+		// The schedule is paused from the moment the token arrives until the manual run finishes, so both never overlap
+		// and a busy schedule can not hold the manual run back.
+		// State edits and resync requests go first, in this order, and pause the schedule the same way.
+		idle := !continuousActive && !hasActiveRun(childJobs.Items, "")
+		startStateEdit := stateEditPending && idle
+		stateEditActive := stateEditPending || hasActiveRun(childJobs.Items, stateEditRunType)
+		startResync := resyncPending && !stateEditPending && idle
+		resyncActive := resyncPending || hasActiveRun(childJobs.Items, resyncRunType)
+		manualRunPending := runNowRequested && !runNowConsumed
+		startRunNow := manualRunPending && !stateEditPending && !resyncPending && idle
+		if manualRunPending && !startRunNow {
+			log.Info("Deferring manual run until active runs finish", "token", runNowToken)
+		}
+		manualRunActive := startRunNow || hasActiveRun(childJobs.Items, manualRunType)
+
+		// Create actual kubernetes jobs to run
+		suspendRuns := manualRunPending || manualRunActive || continuousActive || stateEditActive || resyncActive
+		// CronJobs of a job only forbid concurrent runs of their own, the other CronJobs are paused while one of them
+		// runs so their runs never share the state volume
+		running := runningCronJobs(childJobs.Items)
+		cronJobs, pausedCronJobs, runningNames := []batchv1.CronJob{}, []string{}, []string{}
+		for _, updatedCronJob := range getScheduledCronJobs(&pipelinewiseJob, jobIdentifier, pipelines, configVolume, configData, pwVolume, secretEnv) {
+			suspend := true
+			switch {
+			case suspendRuns:
+				updatedCronJob.Spec.Suspend = &suspend
+			case running[updatedCronJob.Name]:
+				runningNames = append(runningNames, updatedCronJob.Name)
+			case len(running) > 0:
+				updatedCronJob.Spec.Suspend = &suspend
+				pausedCronJobs = append(pausedCronJobs, updatedCronJob.Name)
+			}
+			cronJob, err := r.reconcileCronJob(ctx, &pipelinewiseJob, updatedCronJob)
+			if err != nil {
+				log.Error(err, "Failed to reconcile executor CronJob", "cronjob", updatedCronJob.Name)
+				return r.degraded(ctx, &pipelinewiseJob, "CronJobFailed", err)
+			}
+			cronJobs = append(cronJobs, cronJob)
+		}
+		if _, err := r.deleteStaleCronJobs(ctx, &pipelinewiseJob, jobIdentifier, cronJobs); err != nil {
+			log.Error(err, "Failed to remove stale executor CronJobs")
+			return r.degraded(ctx, &pipelinewiseJob, "CronJobFailed", err)
+		}
+
+		if startStateEdit {
+			stateEditRun, err := r.startStateEdit(ctx, &pipelinewiseJob, updatedExecutorJob)
+			if err != nil {
+				log.Error(err, "Failed to create state edit Job")
+				return r.degraded(ctx, &pipelinewiseJob, "StateEditFailed", err)
+			}
+			childJobs.Items = append(childJobs.Items, *stateEditRun)
+		}
+
+		if startResync {
+			resyncRun, err := r.startResync(ctx, &pipelinewiseJob, updatedExecutorJob)
+			if err != nil {
+				log.Error(err, "Failed to create resync Job")
+				return r.degraded(ctx, &pipelinewiseJob, "ResyncFailed", err)
+			}
+			childJobs.Items = append(childJobs.Items, *resyncRun)
+		}
+
+		if startRunNow {
+			manualRun := getManualRun(&pipelinewiseJob, updatedExecutorJob, runNowToken)
+			if err := ctrl.SetControllerReference(&pipelinewiseJob, &manualRun, r.Scheme); err != nil {
+				return r.degraded(ctx, &pipelinewiseJob, "RunNowFailed", err)
+			}
+			if err := r.Create(ctx, &manualRun); err != nil {
+				if !errors.IsAlreadyExists(err) {
+					log.Error(err, "Failed to create manual run")
+					return r.degraded(ctx, &pipelinewiseJob, "RunNowFailed", err)
+				}
+			} else {
+				childJobs.Items = append(childJobs.Items, manualRun)
+			}
+			pipelinewiseJob.Status.LastManualRun = &batchv1alpha1.ManualRunStatus{
+				Token:         runNowToken,
+				JobName:       manualRun.Name,
+				TriggeredTime: metav1.Now(),
+			}
+			runNowConsumed = true
+		}
+
+		cronJobNames, cronJobSchedules := []string{}, []string{}
+		for _, cronJob := range cronJobs {
+			cronJobNames = append(cronJobNames, cronJob.Name)
+			cronJobSchedules = append(cronJobSchedules, fmt.Sprintf("%v runs on schedule %q", cronJob.Name, cronJob.Spec.Schedule))
+			if cronJob.Status.LastScheduleTime != nil && (pipelinewiseJob.Status.LastScheduleTime == nil || pipelinewiseJob.Status.LastScheduleTime.Before(cronJob.Status.LastScheduleTime)) {
+				pipelinewiseJob.Status.LastScheduleTime = cronJob.Status.LastScheduleTime
+			}
+		}
+		subject := fmt.Sprintf("CronJob %v is", cronJobNames[0])
+		if len(cronJobs) > 1 {
+			subject = fmt.Sprintf("CronJobs %v are", strings.Join(cronJobNames, ", "))
+		}
+		switch {
+		case continuousActive:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "WaitingForRuns", fmt.Sprintf("%v paused until the continuous executor stopped", subject))
+		case stateEditActive:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "StateEditActive", fmt.Sprintf("%v paused while the replication state is edited", subject))
+		case resyncActive:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "ResyncActive", fmt.Sprintf("%v paused while tables are resynced", subject))
+		case manualRunActive:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "ManualRunActive", fmt.Sprintf("%v paused while a manual run is active", subject))
+		case manualRunPending:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "ManualRunPending", fmt.Sprintf("%v paused until the manual run started", subject))
+		case pipelinewiseJob.Spec.Suspend != nil && *pipelinewiseJob.Spec.Suspend:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "Suspended", fmt.Sprintf("%v suspended", subject))
+		case len(pausedCronJobs) > 0:
+			pausedSubject := fmt.Sprintf("CronJob %v is", pausedCronJobs[0])
+			if len(pausedCronJobs) > 1 {
+				pausedSubject = fmt.Sprintf("CronJobs %v are", strings.Join(pausedCronJobs, ", "))
+			}
+			runningSubject := "a scheduled run finished"
+			if len(runningNames) > 0 {
+				runningSubject = fmt.Sprintf("the run of CronJob %v finished", strings.Join(runningNames, ", "))
+			}
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionFalse, "WaitingForRuns", fmt.Sprintf("%v paused until %v", pausedSubject, runningSubject))
+		default:
+			setCondition(&pipelinewiseJob, batchv1alpha1.ConditionScheduled, metav1.ConditionTrue, "CronJobReady", fmt.Sprintf("CronJob %v", strings.Join(cronJobSchedules, ", CronJob ")))
 		}
 	}
 
-	// Create actual kubernetes job to run
-	jobIdentifier := identifiers[JobMapExternalResourceID]
-	var executorJob kbatchv1beta1.CronJob
-	updatedExecutorJob := getExecutorJob(&pipelinewiseJob, jobIdentifier, pwConfig, pwConfigScript, pwVolume)
-	if err := r.Get(ctx, jobIdentifier, &executorJob); err != nil {
-		err = r.Create(ctx, &updatedExecutorJob)
-		if err != nil {
-			log.Error(err, "Failed to create executor Job")
+	updateRunStatus(&pipelinewiseJob, childJobs.Items)
+	updateResyncStatus(&pipelinewiseJob, childJobs.Items)
+	updateStateEditStatus(&pipelinewiseJob, childJobs.Items)
+	newRuns, err := r.updateRunStatistics(ctx, &pipelinewiseJob, childJobs.Items)
+	if err != nil {
+		log.Error(err, "Failed to collect run statistics")
+		return r.degraded(ctx, &pipelinewiseJob, "StatisticsFailed", err)
+	}
+
+	setCondition(&pipelinewiseJob, batchv1alpha1.ConditionDegraded, metav1.ConditionFalse, "Reconciled", "All resources are up to date")
+	pipelinewiseJob.Status.ObservedGeneration = pipelinewiseJob.Generation
+	if !equality.Semantic.DeepEqual(*currentStatus, pipelinewiseJob.Status) {
+		resolvedSpec := pipelinewiseJob.Spec.DeepCopy()
+		if err := r.Status().Update(ctx, &pipelinewiseJob); err != nil {
+			log.Error(err, "Failed to update PipelinewiseJob status")
 			return ctrl.Result{}, err
 		}
-	} else {
-		executorJob.Spec = updatedExecutorJob.Spec
-		err = r.Update(ctx, &executorJob)
-		if err != nil {
-			log.Error(err, "Failed to update executor Job")
+		// The update hands back the stored spec, runs are reported with the referenced taps and targets resolved
+		pipelinewiseJob.Spec = *resolvedSpec
+	}
+	// New runs are only counted and reported once they are recorded in status, a failed update finds them again on retry
+	recordJobMetrics(&pipelinewiseJob, newRuns)
+	r.runEvents(&pipelinewiseJob, currentStatus.Active, newRuns)
+	if runNowSkipped {
+		r.event(&pipelinewiseJob, corev1.EventTypeWarning, "RunNowSkipped", "Manual run %v skipped, the job runs continuously", runNowToken)
+	}
+
+	// Runs are notified once recorded in status, failed deliveries are retried after the metadata is updated
+	notifyErr := r.notifyRuns(ctx, &pipelinewiseJob, newRuns)
+	if notifyErr != nil {
+		log.Error(notifyErr, "Failed to notify runs")
+	}
+
+	// The run is recorded in status by now, drop the token so it is not triggered again
+	metadataChanged := false
+	if runNowConsumed {
+		delete(pipelinewiseJob.Annotations, batchv1alpha1.RunNowAnnotation)
+		metadataChanged = true
+	}
+	// Every generated resource is owned by now, garbage collection takes over the cleanup
+	if containsString(pipelinewiseJob.ObjectMeta.Finalizers, legacyFinalizerID) {
+		pipelinewiseJob.ObjectMeta.Finalizers = removeString(pipelinewiseJob.ObjectMeta.Finalizers, legacyFinalizerID)
+		metadataChanged = true
+	}
+	if metadataChanged {
+		pipelinewiseJob.Spec = *unresolvedSpec
+		if err := r.Update(ctx, &pipelinewiseJob); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, notifyErr
 }
 
-func (r *PipelinewiseJobReconciler) deleteExternalResources(pipelinewiseJob *batchv1alpha1.PipelinewiseJob) error {
-	//
-	// delete any external resources associated with the cronJob
-	//
-	// Ensure that delete implementation is idempotent and safe to invoke
-	// multiple types for same object.
-	identifiers := resourcesIdentifier(pipelinewiseJob)
-	deleteCtx := context.Background()
-	var executorJob kbatchv1beta1.CronJob
-	if err := r.Get(deleteCtx, identifiers[JobMapExternalResourceID], &executorJob); err == nil {
-		// Found external resource job
-		err := r.Delete(deleteCtx, &executorJob)
-		if err != nil {
-			return err
+// degraded records a failed reconciliation on the job status and hands back the original error so the request is retried
+func (r *PipelinewiseJobReconciler) degraded(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, reason string, err error) (ctrl.Result, error) {
+	recordReconcileError(pwJob, reason)
+	r.event(pwJob, corev1.EventTypeWarning, reason, "%v", err.Error())
+	setCondition(pwJob, batchv1alpha1.ConditionDegraded, metav1.ConditionTrue, reason, err.Error())
+	pwJob.Status.ObservedGeneration = pwJob.Generation
+	if statusErr := r.Status().Update(ctx, pwJob); statusErr != nil {
+		r.Log.Error(statusErr, "Failed to update PipelinewiseJob status", "pipelinewisejob", pwJob.Name)
+	}
+	return ctrl.Result{}, err
+}
+
+func setCondition(pwJob *batchv1alpha1.PipelinewiseJob, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&pwJob.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: pwJob.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateRunStatus derives active runs, last success and last run outcome from the Jobs labeled with the job name
+func updateRunStatus(pwJob *batchv1alpha1.PipelinewiseJob, jobs []batchv1.Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+
+	active := []string{}
+	var lastFinished *batchv1.Job
+	var lastFinishedType batchv1.JobConditionType
+	for i := range jobs {
+		job := &jobs[i]
+		finishedType, finishedTime := getJobFinishedStatus(job)
+		if finishedType == "" {
+			active = append(active, job.Name)
+			continue
+		}
+		// State edits do not replicate anything, they are reported in status.lastStateEdit only
+		if job.Labels[runTypeLabel] == stateEditRunType {
+			continue
 		}
 
-		var job batchv1.Job
-		opts := []client.DeleteAllOfOption{
-			client.InNamespace(executorJob.Namespace),
-			client.MatchingLabels{"pwjob-name": pipelinewiseJob.Name},
+		lastFinished = job
+		lastFinishedType = finishedType
+		if finishedType == batchv1.JobComplete && finishedTime != nil {
+			if pwJob.Status.LastSuccessfulTime == nil || pwJob.Status.LastSuccessfulTime.Before(finishedTime) {
+				pwJob.Status.LastSuccessfulTime = finishedTime
+			}
 		}
+	}
+	if len(active) == 0 {
+		active = nil
+	}
+	pwJob.Status.Active = active
 
-		err = r.DeleteAllOf(deleteCtx, &job, opts...)
-		if err != nil {
-			return err
+	switch {
+	case lastFinished == nil:
+		if meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionLastRunSucceeded) == nil {
+			setCondition(pwJob, batchv1alpha1.ConditionLastRunSucceeded, metav1.ConditionUnknown, "NoRunYet", "No run has finished yet")
 		}
+	case lastFinishedType == batchv1.JobComplete:
+		setCondition(pwJob, batchv1alpha1.ConditionLastRunSucceeded, metav1.ConditionTrue, "JobSucceeded", fmt.Sprintf("Job %v finished successfully", lastFinished.Name))
+	case isJobTimedOut(lastFinished):
+		setCondition(pwJob, batchv1alpha1.ConditionLastRunSucceeded, metav1.ConditionFalse, "JobTimedOut", fmt.Sprintf("Job %v timed out after %vs", lastFinished.Name, *lastFinished.Spec.ActiveDeadlineSeconds))
+	default:
+		setCondition(pwJob, batchv1alpha1.ConditionLastRunSucceeded, metav1.ConditionFalse, "JobFailed", fmt.Sprintf("Job %v failed", lastFinished.Name))
 	}
+}
 
-	var volume corev1.PersistentVolumeClaim
-	if err := r.Get(deleteCtx, identifiers[VolumeExternalResourceID], &volume); err == nil {
-		// Found external resource volume
-		err := r.Delete(deleteCtx, &volume)
-		if err != nil {
-			return err
+// getJobFinishedStatus returns the terminal condition type and its time, or an empty type while the job is still running
+func getJobFinishedStatus(job *batchv1.Job) (batchv1.JobConditionType, *metav1.Time) {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			finishedTime := c.LastTransitionTime
+			if c.Type == batchv1.JobComplete && job.Status.CompletionTime != nil {
+				finishedTime = *job.Status.CompletionTime
+			}
+			return c.Type, &finishedTime
 		}
 	}
+	return "", nil
+}
 
-	var config corev1.ConfigMap
-	if err := r.Get(deleteCtx, identifiers[ConfigMapExternalResourceID], &config); err == nil {
-		// Found external resource volume
-		err := r.Delete(deleteCtx, &config)
-		if err != nil {
-			return err
+// isJobTimedOut reports whether the job was terminated for running longer than its active deadline
+func isJobTimedOut(job *batchv1.Job) bool {
+	if job.Spec.ActiveDeadlineSeconds == nil {
+		return false
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Reason == "DeadlineExceeded" {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// hasActiveRun reports whether an unfinished Job carries the given run type label. An empty run type matches every Job
+func hasActiveRun(jobs []batchv1.Job, runType string) bool {
+	for i := range jobs {
+		if runType != "" && jobs[i].Labels[runTypeLabel] != runType {
+			continue
+		}
+		if finishedType, _ := getJobFinishedStatus(&jobs[i]); finishedType == "" {
+			return true
+		}
+	}
+	return false
 }
 
-func getExecutorJob(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, pwConfig, pwConfigScript corev1.ConfigMap, pwVolume corev1.PersistentVolumeClaim) kbatchv1beta1.CronJob {
-	imageName := fmt.Sprintf("dirathea/pipelinewise:%v-%v-%v", viper.GetString("PIPELINEWISE_VERSION"), batchv1alpha1.GetTapConnectorID(pwJob), batchv1alpha1.GetTargetConnectorID(pwJob))
-	if pwJob.Spec.Image != nil {
-		imageName = *pwJob.Spec.Image
+// getManualRun builds a one-off Job from the executor CronJob template, named after the run-now token so each token runs once
+func getManualRun(pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob, token string) batchv1.Job {
+	return getOneOffRun(pwJob, executorJob, "run", manualRunType, token)
+}
+
+// getOneOffRun builds a Job of the given run type from the executor CronJob template, named after the token
+func getOneOffRun(pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob, nameInfix, runType, token string) batchv1.Job {
+	tokenHash := fnv.New32a()
+	tokenHash.Write([]byte(token))
+
+	labels := map[string]string{}
+	for k, v := range executorJob.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	labels[pwJobNameLabel] = pwJob.Name
+	labels[runTypeLabel] = runType
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v-%v-%x", executorJob.Name, nameInfix, tokenHash.Sum32()),
+			Namespace: pwJob.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				"cronjob.kubernetes.io/instantiate": "manual",
+			},
+		},
+		Spec: *executorJob.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+}
+
+// deleteExternalResources removes generated resources which are not controlled by the PipelinewiseJob.
+// Controlled resources are left to the Kubernetes garbage collector, retained state volumes are kept.
+func (r *PipelinewiseJobReconciler) deleteExternalResources(ctx context.Context, pipelinewiseJob *batchv1alpha1.PipelinewiseJob) error {
+	//
+	// Ensure that delete implementation is idempotent and safe to invoke
+	// multiple types for same object.
+	identifiers := resourcesIdentifier(pipelinewiseJob)
+	externalResources := []struct {
+		id     ExternalResourceID
+		object client.Object
+	}{
+		{JobMapExternalResourceID, r.cronJobObject()},
+		{VolumeExternalResourceID, &corev1.PersistentVolumeClaim{}},
+		{ConfigMapExternalResourceID, &corev1.ConfigMap{}},
+		{ConfigMapExternalResourceID, &corev1.Secret{}},
+	}
+
+	for _, resource := range externalResources {
+		// Resources of earlier operator versions are missing the job name label the cache selects Secrets by
+		if err := r.apiReader().Get(ctx, identifiers[resource.id], resource.object); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if metav1.IsControlledBy(resource.object, pipelinewiseJob) {
+			continue
+		}
+		if resource.id == VolumeExternalResourceID && pipelinewiseJob.Spec.Volume != nil && pipelinewiseJob.Spec.Volume.RetainOnDelete {
+			continue
+		}
+		// Background propagation removes the Jobs spawned by the CronJob as well
+		if err := r.Delete(ctx, resource.object, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// getExecutorJob builds the executor CronJob running the given pipelines of the job one after the other
+func getExecutorJob(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, pipelines []batchv1alpha1.Pipeline, configVolume corev1.VolumeSource, configData map[string]string, pwVolume corev1.PersistentVolumeClaim, secretEnv []corev1.EnvVar) batchv1.CronJob {
 	volumes := []corev1.Volume{
 		{
-			Name: "pipelinewise-configuration",
-			VolumeSource: corev1.VolumeSource{
-				ConfigMap: &corev1.ConfigMapVolumeSource{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: pwConfig.Name,
-					},
-				},
-			},
+			Name:         "pipelinewise-configuration",
+			VolumeSource: configVolume,
 		},
 		{
 			Name: "runtime-volume",
@@ -254,10 +633,6 @@ func getExecutorJob(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.Name
 	}
 
 	volumeMounts := []corev1.VolumeMount{
-		{
-			Name:      "pipelinewise-configuration",
-			MountPath: "/configurations",
-		},
 		{
 			Name:      "runtime-volume",
 			MountPath: "/root/.pipelinewise",
@@ -265,160 +640,447 @@ func getExecutorJob(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.Name
 	}
 
 	importArgs := []string{
-		"-c",
-		"/app/entrypoint.sh import --dir /configurations",
-	}
-
-	runnerArgs := []string{
-		"run_tap",
-		"--tap",
-		string(batchv1alpha1.GetTapID(pwJob)),
-		"--target",
-		string(batchv1alpha1.GetTargetID(pwJob)),
-		"--extra_log",
+		"import",
+		"--dir",
+		"/configurations",
 	}
 
 	if pwJob.Spec.Secret != nil {
 		// Add secret as volume
-		configModeDefaultMode := int32(0755)
-		volumes = append(volumes,
-			corev1.Volume{
-				Name: "pw-master-password",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: pwJob.Spec.Secret.Name,
-						Items: []corev1.KeyToPath{
-							{
-								Key:  pwJob.Spec.Secret.Key,
-								Path: "master-password",
-							},
+		volumes = append(volumes, corev1.Volume{
+			Name: "pw-master-password",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: pwJob.Spec.Secret.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  pwJob.Spec.Secret.Key,
+							Path: "master-password",
 						},
 					},
 				},
 			},
-			corev1.Volume{
-				Name: "pw-config-mod",
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: pwConfigScript.Name,
-						},
-						DefaultMode: &configModeDefaultMode,
-					},
-				},
-			})
+		})
 
 		// Add secret volume mount
-		volumeMounts = append(volumeMounts,
-			corev1.VolumeMount{
-				Name:      "pw-master-password",
-				MountPath: "/secrets",
-			},
-			corev1.VolumeMount{
-				Name:      "pw-config-mod",
-				MountPath: "/pw-scripts",
-			})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "pw-master-password",
+			MountPath: "/secrets",
+		})
 
-		// Append master token params
-		importArgs[1] = fmt.Sprintf("/pw-scripts/%v /configurations /config-mod && /app/entrypoint.sh import --dir /config-mod --secret /secrets/master-password", scriptFileName)
+		// Append master token params to decrypt `!vault` values
+		importArgs = append(importArgs, "--secret", "/secrets/master-password")
 	}
 
-	return kbatchv1beta1.CronJob{
+	// Both pipelinewise commands log through the rendered logging configuration
+	loggingEnv := getLoggingEnv(configData)
+
+	// Every pipeline imports its own configuration right before it runs, since `import` only registers the taps it finds
+	steps := []pipelineStep{}
+	for _, pipeline := range pipelines {
+		imageName := fmt.Sprintf("dirathea/pipelinewise:%v-%v-%v", viper.GetString("PIPELINEWISE_VERSION"), pipeline.Tap.ConnectorID(), pipeline.Target.ConnectorID())
+		if pwJob.Spec.Image != nil {
+			imageName = *pwJob.Spec.Image
+		}
+		importName, runnerName := pipelineContainerNames(pipelines, pipeline)
+		pipelineMounts := append(pipelineConfigMounts(pwJob, pipeline, configData), volumeMounts...)
+
+		runnerArgs := []string{
+			"run_tap",
+			"--tap",
+			string(pipeline.TapID()),
+			"--target",
+			string(pipeline.TargetID()),
+			"--extra_log",
+		}
+
+		steps = append(steps, pipelineStep{
+			importer: corev1.Container{
+				Name:                     importName,
+				Image:                    imageName,
+				Args:                     importArgs,
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				Env:                      append(append([]corev1.EnvVar(nil), loggingEnv...), secretEnv...),
+				VolumeMounts:             pipelineMounts,
+			},
+			runner: corev1.Container{
+				Name:         runnerName,
+				Image:        imageName,
+				Command:      []string{"/bin/bash", "-c", runnerScript, "runner"},
+				Args:         runnerArgs,
+				Env:          append([]corev1.EnvVar(nil), loggingEnv...),
+				VolumeMounts: pipelineMounts,
+			},
+		})
+	}
+
+	executorJob := batchv1.CronJob{
 		ObjectMeta: identifierToMeta(identifier),
-		Spec: kbatchv1beta1.CronJobSpec{
+		Spec: batchv1.CronJobSpec{
 			Schedule:                   pwJob.Spec.Schedule,
 			Suspend:                    pwJob.Spec.Suspend,
+			ConcurrencyPolicy:          batchv1alpha1.GetConcurrencyPolicy(pwJob),
+			StartingDeadlineSeconds:    pwJob.Spec.StartingDeadlineSeconds,
 			SuccessfulJobsHistoryLimit: pwJob.Spec.SuccessfulJobsHistoryLimit,
 			FailedJobsHistoryLimit:     pwJob.Spec.FailedJobsHistoryLimit,
-			JobTemplate: kbatchv1beta1.JobTemplateSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
-						"pwjob-name": pwJob.Name,
+						pwJobNameLabel: pwJob.Name,
 					},
 				},
 				Spec: batchv1.JobSpec{
+					BackoffLimit:            pwJob.Spec.BackoffLimit,
+					ActiveDeadlineSeconds:   pwJob.Spec.ActiveDeadlineSeconds,
+					TTLSecondsAfterFinished: pwJob.Spec.TTLSecondsAfterFinished,
 					Template: corev1.PodTemplateSpec{
 						Spec: corev1.PodSpec{
 							RestartPolicy: corev1.RestartPolicyNever,
-							InitContainers: []corev1.Container{
-								{
-									Name:  "import",
-									Image: imageName,
-									Args:  importArgs,
-									Command: []string{
-										"/bin/bash",
-									},
-									VolumeMounts: volumeMounts,
-								},
-							},
-							Containers: []corev1.Container{
-								{
-									Name:         "runner",
-									Image:        imageName,
-									Args:         runnerArgs,
-									VolumeMounts: volumeMounts,
-								},
-							},
-							Volumes: volumes,
+							Volumes:       volumes,
 						},
 					},
 				},
 			},
 		},
 	}
+	setPipelineSteps(&executorJob.Spec.JobTemplate.Spec.Template.Spec, steps)
+	applyPodTemplate(&executorJob.Spec.JobTemplate.Spec.Template, pwJob.Spec.PodTemplate)
+
+	return executorJob
 }
 
-func (r *PipelinewiseJobReconciler) getConfig(pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName) (corev1.ConfigMap, error) {
-	pwConfig := corev1.ConfigMap{}
-	// Create Pipelinewise Configuration via ConfigMap
-	tapYaml, err := batchv1alpha1.ConstructTapConfiguration(pwJob)
-	if err != nil {
+// getConfig renders tap and target configuration files together with the main configuration, if there is one.
+// Every tap of a job with several taps is rendered into its own file, as well as every copy of a tap loaded into several targets.
+// Fields referencing a Secret are rendered as environment variable lookups, the returned environment variables inject
+// the referenced values into the import containers.
+// Encrypted jobs read referenced values instead and render every sensitive value as an encrypted string.
+func (r *PipelinewiseJobReconciler) getConfig(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob) (map[string]string, []corev1.EnvVar, error) {
+	secretEnv := []corev1.EnvVar{}
+	resolver := func(prefix string) batchv1alpha1.SecretValueResolver {
+		return secretEnvResolver(prefix, &secretEnv)
+	}
+	encrypter := func(prefix string) batchv1alpha1.SensitiveValueEncrypter {
+		return nil
+	}
+	if pwJob.Spec.Encrypted {
+		if pwJob.Spec.Secret == nil {
+			return nil, nil, fmt.Errorf("Master password secret is required to encrypt the configuration")
+		}
+		masterPassword, err := r.getSecretValue(ctx, pwJob.Namespace, &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: pwJob.Spec.Secret.Name},
+			Key:                  pwJob.Spec.Secret.Key,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		resolver = func(prefix string) batchv1alpha1.SecretValueResolver {
+			return r.secretValueResolver(ctx, pwJob.Namespace)
+		}
+		encrypter = func(prefix string) batchv1alpha1.SensitiveValueEncrypter {
+			return vaultEncrypter(prefix, masterPassword)
+		}
+	}
+
+	pipelines := batchv1alpha1.GetPipelines(pwJob)
+	if len(pipelines) == 0 {
+		err := fmt.Errorf("No Valid Tap configured")
 		r.Log.Error(err, "Failed to construct tap configuration")
-		return pwConfig, err
+		return nil, nil, err
+	}
+
+	configData := map[string]string{}
+	for _, pipeline := range pipelines {
+		// Referenced values of several pipelines are injected into the same import containers, so their variables are named after the tap
+		tapPrefix := "tap"
+		if len(pipelines) > 1 {
+			tapPrefix = fmt.Sprintf("tap_%v", pipeline.TapID())
+		}
+		// The webhook rejects taps and targets sharing an id, referenced ones may still collide once resolved
+		if _, ok := configData[tapConfigFileName(pipeline)]; ok {
+			err := fmt.Errorf("Several taps of the job render tap id %v into the same target", pipeline.TapID())
+			r.Log.Error(err, "Failed to construct tap configuration", "tap", pipeline.TapID())
+			return nil, nil, err
+		}
+		tapYaml, err := batchv1alpha1.ConstructPipelineTapConfiguration(pipeline, resolver(tapPrefix), encrypter(tapPrefix))
+		if err != nil {
+			r.Log.Error(err, "Failed to construct tap configuration", "tap", pipeline.TapID())
+			return nil, nil, err
+		}
+		configData[tapConfigFileName(pipeline)] = string(tapYaml)
+	}
+	for _, pipeline := range pipelines {
+		// Every target is rendered once, however many taps are loaded into it
+		if _, ok := configData[targetConfigFileName(pipeline)]; ok {
+			continue
+		}
+		targetPrefix := "target"
+		if len(pwJob.Spec.Targets) > 1 {
+			targetPrefix = fmt.Sprintf("target_%v", pipeline.TargetID())
+		}
+		targetYaml, err := batchv1alpha1.ConstructPipelineTargetConfiguration(pipeline, resolver(targetPrefix), encrypter(targetPrefix))
+		if err != nil {
+			r.Log.Error(err, "Failed to construct target configuration", "target", pipeline.TargetID())
+			return nil, nil, err
+		}
+		configData[targetConfigFileName(pipeline)] = string(targetYaml)
+	}
+
+	mainConfig, err := r.getMainConfig(ctx, pwJob)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mainConfig != nil {
+		mainYaml, err := batchv1alpha1.ConstructMainConfiguration(mainConfig, resolver("config"), encrypter("config"))
+		if err != nil {
+			r.Log.Error(err, "Failed to construct main configuration")
+			return nil, nil, err
+		}
+		configData[batchv1alpha1.MainConfigFileName] = string(mainYaml)
+		if mainConfig.Logging != "" {
+			configData[batchv1alpha1.LoggingConfigFileName] = mainConfig.Logging
+		}
+	}
+	return configData, secretEnv, nil
+}
+
+// reconcileConfig stores the rendered configuration in the Secret or ConfigMap selected by the job config storage and
+// returns the volume source mounting it. Configuration left in the other kind after the storage changed is removed,
+// so connection settings do not linger in a ConfigMap once the job moved to a Secret.
+func (r *PipelinewiseJobReconciler) reconcileConfig(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName, data map[string]string) (corev1.VolumeSource, error) {
+	var config, staleConfig client.Object
+	var setData func()
+	var volumeSource corev1.VolumeSource
+	switch batchv1alpha1.GetConfigStorage(pwJob) {
+	case batchv1alpha1.ConfigMapConfigStorage:
+		configMap := &corev1.ConfigMap{}
+		config, staleConfig = configMap, &corev1.Secret{}
+		setData = func() {
+			configMap.Data = data
+		}
+		volumeSource.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: identifier.Name,
+			},
+		}
+	default:
+		secret := &corev1.Secret{}
+		config, staleConfig = secret, &corev1.ConfigMap{}
+		setData = func() {
+			secret.Type = corev1.SecretTypeOpaque
+			secret.Data = map[string][]byte{}
+			for key, value := range data {
+				secret.Data[key] = []byte(value)
+			}
+		}
+		volumeSource.Secret = &corev1.SecretVolumeSource{
+			SecretName: identifier.Name,
+		}
+	}
+
+	err := r.getLabeled(ctx, identifier, config)
+	if err != nil && !errors.IsNotFound(err) {
+		return volumeSource, err
+	}
+	configExists := err == nil
+	if !configExists {
+		config.SetName(identifier.Name)
+		config.SetNamespace(identifier.Namespace)
+	}
+	// Configurations rendered by earlier operator versions are labeled once, which does not change their content
+	labeled := config.GetLabels()[pwJobNameLabel] == pwJob.Name
+	config.SetLabels(mergeStringMap(config.GetLabels(), map[string]string{pwJobNameLabel: pwJob.Name}))
+	setData()
+	if err := ctrl.SetControllerReference(pwJob, config, r.Scheme); err != nil {
+		return volumeSource, err
+	}
+	resourceVersion := config.GetResourceVersion()
+	if configExists {
+		err = r.Update(ctx, config)
+	} else {
+		err = r.Create(ctx, config)
 	}
-	targetYaml, err := batchv1alpha1.ConstructTargetConfiguration(pwJob)
 	if err != nil {
-		r.Log.Error(err, "Failed to construct target configuration")
-		return pwConfig, err
+		return volumeSource, err
+	}
+	if !configExists {
+		r.event(pwJob, corev1.EventTypeNormal, "ConfigRendered", "Configuration rendered into %v %v", batchv1alpha1.GetConfigStorage(pwJob), identifier.Name)
+	} else if labeled && config.GetResourceVersion() != resourceVersion {
+		r.event(pwJob, corev1.EventTypeNormal, "ConfigChanged", "Configuration in %v %v changed", batchv1alpha1.GetConfigStorage(pwJob), identifier.Name)
+	}
+
+	if err := r.Get(ctx, identifier, staleConfig); err == nil && metav1.IsControlledBy(staleConfig, pwJob) {
+		if err := r.Delete(ctx, staleConfig); err != nil && !errors.IsNotFound(err) {
+			return volumeSource, err
+		}
+	}
+
+	return volumeSource, nil
+}
+
+// deleteLegacyConfigScript removes the configuration script ConfigMap created by earlier operator versions in the job
+// namespace. It was created without owner references, a ConfigMap of the same name not holding the script is kept
+func (r *PipelinewiseJobReconciler) deleteLegacyConfigScript(ctx context.Context, namespace string) error {
+	var configScript corev1.ConfigMap
+	if err := r.Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: legacyConfigScriptName}, &configScript); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, ok := configScript.Data[legacyConfigScriptKey]; !ok || len(configScript.OwnerReferences) > 0 {
+		return nil
+	}
+	r.Log.Info("Removing configuration script of earlier operator versions", "configmap", client.ObjectKeyFromObject(&configScript))
+	return client.IgnoreNotFound(r.Delete(ctx, &configScript))
+}
+
+// secretEnvResolver renders Secret references as environment variable lookups which pipelinewise resolves
+// while importing the configuration, so referenced values never appear in the rendered configuration.
+// The rendered value is single quoted by yaml, hence quotes in the value are escaped the same way.
+func secretEnvResolver(prefix string, secretEnv *[]corev1.EnvVar) batchv1alpha1.SecretValueResolver {
+	return func(fieldPath []string, source batchv1alpha1.ValueFromSource) (string, error) {
+		envName := secretEnvName(append([]string{prefix}, fieldPath...))
+		*secretEnv = append(*secretEnv, corev1.EnvVar{
+			Name: envName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: source.SecretKeyRef,
+			},
+		})
+		return fmt.Sprintf(`{{ env_var["%v"] | replace("\x27", "\x27\x27") }}`, envName), nil
+	}
+}
+
+// secretValueResolver renders Secret references with the referenced value, so it could be encrypted by the operator
+func (r *PipelinewiseJobReconciler) secretValueResolver(ctx context.Context, namespace string) batchv1alpha1.SecretValueResolver {
+	return func(fieldPath []string, source batchv1alpha1.ValueFromSource) (string, error) {
+		value, err := r.getSecretValue(ctx, namespace, source.SecretKeyRef)
+		return string(value), err
+	}
+}
+
+// getSecretValue reads the selected Secret key from the API server. Missing optional keys resolve to an empty value
+func (r *PipelinewiseJobReconciler) getSecretValue(ctx context.Context, namespace string, selector *corev1.SecretKeySelector) ([]byte, error) {
+	optional := selector.Optional != nil && *selector.Optional
+	var secret corev1.Secret
+	if err := r.apiReader().Get(ctx, ktypes.NamespacedName{Namespace: namespace, Name: selector.Name}, &secret); err != nil {
+		if errors.IsNotFound(err) && optional {
+			return []byte{}, nil
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok && !optional {
+		return nil, fmt.Errorf("Key %v not found in secret %v", selector.Key, selector.Name)
+	}
+	return value, nil
+}
+
+// secretEnvName builds the environment variable name holding a referenced value, e.g. PW_TARGET_PASSWORD
+func secretEnvName(fieldPath []string) string {
+	envName := strings.ToUpper(strings.Join(append([]string{"pw"}, fieldPath...), "_"))
+	return invalidEnvNameChars.ReplaceAllString(envName, "_")
+}
+
+// reconcileVolume ensures the state volume of the job exists and returns it. Generated claims are owned by the job
+// unless they are retained on delete, and expanded when the requested size grows
+func (r *PipelinewiseJobReconciler) reconcileVolume(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, identifier ktypes.NamespacedName) (corev1.PersistentVolumeClaim, error) {
+	var pwVolume corev1.PersistentVolumeClaim
+	volumeSpec := batchv1alpha1.VolumeSpec{}
+	if pwJob.Spec.Volume != nil {
+		volumeSpec = *pwJob.Spec.Volume
+	}
+
+	if volumeSpec.ExistingClaimName != "" {
+		err := r.Get(ctx, ktypes.NamespacedName{Namespace: pwJob.Namespace, Name: volumeSpec.ExistingClaimName}, &pwVolume)
+		return pwVolume, err
+	}
+
+	desiredVolume := getVolume(identifier, volumeSpec)
+	if err := r.Get(ctx, identifier, &pwVolume); err != nil {
+		if !errors.IsNotFound(err) {
+			return pwVolume, err
+		}
+		if !volumeSpec.RetainOnDelete {
+			if err := ctrl.SetControllerReference(pwJob, &desiredVolume, r.Scheme); err != nil {
+				return pwVolume, err
+			}
+		}
+		if err := r.Create(ctx, &desiredVolume); err != nil {
+			return desiredVolume, err
+		}
+		r.event(pwJob, corev1.EventTypeNormal, "VolumeCreated", "PersistentVolumeClaim %v created", desiredVolume.Name)
+		return desiredVolume, nil
+	}
+
+	volumeChanged := false
+	if controlled := metav1.IsControlledBy(&pwVolume, pwJob); volumeSpec.RetainOnDelete && controlled {
+		// Release the volume from garbage collection, so it outlives the job
+		pwVolume.OwnerReferences = removeOwnerReference(pwVolume.OwnerReferences, pwJob.UID)
+		volumeChanged = true
+	} else if !volumeSpec.RetainOnDelete && !controlled {
+		// Adopt volume created before owner references were set or retained by a previous job
+		if err := ctrl.SetControllerReference(pwJob, &pwVolume, r.Scheme); err != nil {
+			return pwVolume, err
+		}
+		volumeChanged = true
+	}
+
+	desiredSize := desiredVolume.Spec.Resources.Requests[corev1.ResourceStorage]
+	currentSize := pwVolume.Spec.Resources.Requests[corev1.ResourceStorage]
+	if desiredSize.Cmp(currentSize) > 0 {
+		expandable, err := r.isVolumeExpandable(ctx, &pwVolume)
+		if err != nil {
+			return pwVolume, err
+		}
+		if expandable {
+			pwVolume.Spec.Resources.Requests[corev1.ResourceStorage] = desiredSize
+			volumeChanged = true
+		} else {
+			r.Log.Info("State volume can not be expanded", "volume", identifier, "size", currentSize.String(), "requestedSize", desiredSize.String())
+		}
 	}
 
-	pwConfig.ObjectMeta = identifierToMeta(identifier)
+	if volumeChanged {
+		return pwVolume, r.Update(ctx, &pwVolume)
+	}
+	return pwVolume, nil
+}
 
-	tapKeyName := fmt.Sprintf("tap_%v.yaml", batchv1alpha1.GetTapID(pwJob))
-	targetKeyName := fmt.Sprintf("target_%v.yaml", batchv1alpha1.GetTargetID(pwJob))
-	pwConfig.Data = map[string]string{
-		tapKeyName:    string(tapYaml),
-		targetKeyName: string(targetYaml),
+// isVolumeExpandable reports whether the claim is bound and its storage class allows volume expansion
+func (r *PipelinewiseJobReconciler) isVolumeExpandable(ctx context.Context, pwVolume *corev1.PersistentVolumeClaim) (bool, error) {
+	if pwVolume.Status.Phase != corev1.ClaimBound || pwVolume.Spec.StorageClassName == nil || *pwVolume.Spec.StorageClassName == "" {
+		return false, nil
 	}
-	return pwConfig, nil
+	var storageClass storagev1.StorageClass
+	if err := r.Get(ctx, ktypes.NamespacedName{Name: *pwVolume.Spec.StorageClassName}, &storageClass); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
 }
 
-func defaultVolume(identifier ktypes.NamespacedName) corev1.PersistentVolumeClaim {
+func getVolume(identifier ktypes.NamespacedName, volumeSpec batchv1alpha1.VolumeSpec) corev1.PersistentVolumeClaim {
+	size := kresource.MustParse("1Gi")
+	if volumeSpec.Size != nil {
+		size = volumeSpec.Size.DeepCopy()
+	}
+	accessModes := []corev1.PersistentVolumeAccessMode{
+		corev1.ReadWriteOnce,
+	}
+	if len(volumeSpec.AccessModes) > 0 {
+		accessModes = volumeSpec.AccessModes
+	}
+
 	return corev1.PersistentVolumeClaim{
 		ObjectMeta: identifierToMeta(identifier),
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
-			},
+			AccessModes:      accessModes,
+			StorageClassName: volumeSpec.StorageClassName,
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: kresource.MustParse("1Gi"),
+					corev1.ResourceStorage: size,
 				},
 			},
 		},
 	}
 }
 
-func getConfigScript(identifier ktypes.NamespacedName) corev1.ConfigMap {
-	configBytes, _ := ioutil.ReadFile(scriptFileName)
-	return corev1.ConfigMap{
-		ObjectMeta: identifierToMeta(identifier),
-		Data: map[string]string{
-			scriptFileName: string(configBytes),
-		},
-	}
-}
-
 // Helper function to get external resources identifier
 func resourcesIdentifierGenerator(pwJob *batchv1alpha1.PipelinewiseJob, prefix string) ktypes.NamespacedName {
 	return ktypes.NamespacedName{
@@ -432,10 +1094,6 @@ func resourcesIdentifier(pwJob *batchv1alpha1.PipelinewiseJob) map[ExternalResou
 		ConfigMapExternalResourceID: resourcesIdentifierGenerator(pwJob, "pw-config"),
 		VolumeExternalResourceID:    resourcesIdentifierGenerator(pwJob, "pw-volume"),
 		JobMapExternalResourceID:    resourcesIdentifierGenerator(pwJob, "pw-job"),
-		ConfigScriptExternalResourceID: {
-			Name:      configModResourceName,
-			Namespace: pwJob.Namespace,
-		},
 	}
 }
 
@@ -456,6 +1114,16 @@ func containsString(slice []string, s string) bool {
 	return false
 }
 
+func removeOwnerReference(ownerReferences []metav1.OwnerReference, uid ktypes.UID) (result []metav1.OwnerReference) {
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.UID == uid {
+			continue
+		}
+		result = append(result, ownerReference)
+	}
+	return
+}
+
 func removeString(slice []string, s string) (result []string) {
 	for _, item := range slice {
 		if item == s {
@@ -466,9 +1134,31 @@ func removeString(slice []string, s string) (result []string) {
 	return
 }
 
+// jobToPipelinewiseJob maps Jobs spawned by the executor CronJob back to their PipelinewiseJob.
+// These Jobs are controlled by the CronJob, so they are matched by label instead of owner reference.
+func jobToPipelinewiseJob(obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[pwJobNameLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: ktypes.NamespacedName{Namespace: obj.GetNamespace(), Name: name}},
+	}
+}
+
 // SetupWithManager operator manager entrypoint
 func (r *PipelinewiseJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchv1alpha1.PipelinewiseJob{}).
+		Owns(r.cronJobObject()).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Watches(&source.Kind{Type: &batchv1.Job{}}, handler.EnqueueRequestsFromMapFunc(jobToPipelinewiseJob)).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(continuousPodToPipelinewiseJob)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.mainConfigToPipelinewiseJobs)).
+		Watches(&source.Kind{Type: &batchv1alpha1.PipelinewiseTap{}}, handler.EnqueueRequestsFromMapFunc(r.jobsReferencing((*batchv1alpha1.PipelinewiseJob).ReferencesTap))).
+		Watches(&source.Kind{Type: &batchv1alpha1.PipelinewiseTarget{}}, handler.EnqueueRequestsFromMapFunc(r.jobsReferencing((*batchv1alpha1.PipelinewiseJob).ReferencesTarget))).
 		Complete(r)
 }
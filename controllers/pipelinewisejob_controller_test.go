@@ -18,17 +18,32 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
-	kbatchv1beta1 "k8s.io/api/batch/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("PipelinewiseJob Controller", func() {
@@ -110,23 +125,23 @@ var _ = Describe("PipelinewiseJob Controller", func() {
 				}, timeout, interval).Should(BeTrue())
 				Expect(createdPwJob).ShouldNot(BeNil())
 
-				By("Creating Pipelinewise configuration as ConfigMap")
+				By("Creating Pipelinewise configuration as Secret")
 				pwConfigLookupKey := types.NamespacedName{Name: tc.ConfigName, Namespace: jobNamespace}
-				createdConfigMap := &corev1.ConfigMap{}
+				createdConfig := &corev1.Secret{}
 
 				Eventually(func() bool {
-					err := k8sClient.Get(ctx, pwConfigLookupKey, createdConfigMap)
+					err := k8sClient.Get(ctx, pwConfigLookupKey, createdConfig)
 					if err != nil {
 						return false
 					}
 					return true
 				}, timeout, interval).Should(BeTrue())
-				Expect(createdConfigMap.Data).Should(ContainElements(ContainSubstring(tc.ConfigAssertionValue)))
+				Expect(createdConfig.Data).Should(ContainElements(ContainSubstring(tc.ConfigAssertionValue)))
 
 				By("Creating Cronjob")
 				cronJobName := fmt.Sprintf("pw-job-%v", tc.JobName)
 				pwCronJobLookupKey := types.NamespacedName{Name: cronJobName, Namespace: jobNamespace}
-				createdCronJob := &kbatchv1beta1.CronJob{}
+				createdCronJob := &batchv1.CronJob{}
 
 				Eventually(func() bool {
 					err := k8sClient.Get(ctx, pwCronJobLookupKey, createdCronJob)
@@ -654,4 +669,2138 @@ var _ = Describe("PipelinewiseJob Controller", func() {
 			}),
 		)
 	})
+
+	Context("When reconciling PipelinewiseJob", func() {
+		It("Should report status of configuration, schedule and runs", func() {
+			ctx := context.Background()
+			jobName := "status-report"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Reporting rendered configuration and schedule")
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				return pwJob.Status.ObservedGeneration == pwJob.Generation &&
+					meta.IsStatusConditionTrue(pwJob.Status.Conditions, batchv1alpha1.ConditionConfigRendered) &&
+					meta.IsStatusConditionTrue(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled) &&
+					meta.IsStatusConditionFalse(pwJob.Status.Conditions, batchv1alpha1.ConditionDegraded)
+			}, timeout, interval).Should(BeTrue())
+			lastRun := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionLastRunSucceeded)
+			Expect(lastRun).ShouldNot(BeNil())
+			Expect(lastRun.Status).Should(Equal(metav1.ConditionUnknown))
+
+			By("Reporting a finished run")
+			run := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-job-%v-1", jobName),
+					Namespace: jobNamespace,
+					Labels: map[string]string{
+						"pwjob-name": jobName,
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "runner",
+									Image: "runner",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+			completionTime := metav1.Now()
+			run.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+
+			// Suspending the job triggers a new reconciliation
+			suspend := true
+			pwJob.Spec.Suspend = &suspend
+			Expect(k8sClient.Update(ctx, pwJob)).Should(Succeed())
+
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(pwJob.Status.Conditions, batchv1alpha1.ConditionLastRunSucceeded) &&
+					meta.IsStatusConditionFalse(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled)
+			}, timeout, interval).Should(BeTrue())
+			Expect(pwJob.Status.LastSuccessfulTime).ShouldNot(BeNil())
+			Expect(pwJob.Status.Active).Should(BeEmpty())
+		})
+
+		It("Should apply run limits and report timed out runs", func() {
+			ctx := context.Background()
+			jobName := "run-limits"
+			startingDeadlineSeconds := int64(300)
+			backoffLimit := int32(2)
+			activeDeadlineSeconds := int64(3600)
+			ttlSecondsAfterFinished := int32(86400)
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule:                cron,
+					StartingDeadlineSeconds: &startingDeadlineSeconds,
+					BackoffLimit:            &backoffLimit,
+					ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+					TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+					Tap:                     defaultTapSpec,
+					Target:                  defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Forbidding concurrent runs by default")
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdCronJob.Spec.ConcurrencyPolicy).Should(Equal(batchv1.ForbidConcurrent))
+			Expect(createdCronJob.Spec.StartingDeadlineSeconds).Should(Equal(&startingDeadlineSeconds))
+			jobSpec := createdCronJob.Spec.JobTemplate.Spec
+			Expect(jobSpec.BackoffLimit).Should(Equal(&backoffLimit))
+			Expect(jobSpec.ActiveDeadlineSeconds).Should(Equal(&activeDeadlineSeconds))
+			Expect(jobSpec.TTLSecondsAfterFinished).Should(Equal(&ttlSecondsAfterFinished))
+
+			By("Reporting a run which exceeded its deadline")
+			run := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-job-%v-1", jobName),
+					Namespace: jobNamespace,
+					Labels:    createdCronJob.Spec.JobTemplate.Labels,
+				},
+				Spec: *jobSpec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+			failedTime := metav1.Now()
+			run.Status = batchv1.JobStatus{
+				StartTime: &failedTime,
+				Failed:    1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobFailed,
+						Status:             corev1.ConditionTrue,
+						Reason:             "DeadlineExceeded",
+						LastTransitionTime: failedTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return ""
+				}
+				lastRun := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionLastRunSucceeded)
+				if lastRun == nil || lastRun.Status != metav1.ConditionFalse {
+					return ""
+				}
+				return lastRun.Reason
+			}, timeout, interval).Should(Equal("JobTimedOut"))
+
+			By("Allowing concurrent runs on request")
+			pwJob.Spec.ConcurrencyPolicy = batchv1.AllowConcurrent
+			Expect(k8sClient.Update(ctx, pwJob)).Should(Succeed())
+			Eventually(func() batchv1.ConcurrencyPolicy {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: createdCronJob.Name, Namespace: jobNamespace}, createdCronJob); err != nil {
+					return ""
+				}
+				return createdCronJob.Spec.ConcurrencyPolicy
+			}, timeout, interval).Should(Equal(batchv1.AllowConcurrent))
+		})
+
+		It("Should record statistics of the latest runs", func() {
+			ctx := context.Background()
+			jobName := "run-statistics"
+			runHistoryLimit := int32(1)
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule:        cron,
+					RunHistoryLimit: &runHistoryLimit,
+					Tap:             defaultTapSpec,
+					Target:          defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			runner := createdCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+			Expect(runner.Command[:2]).Should(Equal([]string{"/bin/bash", "-c"}))
+			Expect(runner.Args[0]).Should(Equal("run_tap"))
+
+			By("Reading the run summary from the termination message of the runner")
+			startTime := metav1.NewTime(time.Now().Add(-90 * time.Second))
+			completionTime := metav1.Now()
+			run := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-job-%v-1", jobName),
+					Namespace: jobNamespace,
+					Labels:    createdCronJob.Spec.JobTemplate.Labels,
+				},
+				Spec: *createdCronJob.Spec.JobTemplate.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+			runPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%v-pod", run.Name),
+					Namespace: jobNamespace,
+					Labels:    map[string]string{"job-name": run.Name},
+				},
+				Spec: *run.Spec.Template.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, runPod)).Should(Succeed())
+			runPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name: "runner",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+							Message:  `{"tables":[{"name":"orders","rowsExtracted":15,"rowsLoaded":15},{"name":"customers","rowsExtracted":0,"rowsLoaded":0}]}`,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, runPod)).Should(Succeed())
+			run.Status = batchv1.JobStatus{
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() []batchv1alpha1.RunStatistics {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return nil
+				}
+				return pwJob.Status.RecentRuns
+			}, timeout, interval).Should(HaveLen(1))
+			recorded := pwJob.Status.RecentRuns[0]
+			Expect(recorded.JobName).Should(Equal(run.Name))
+			Expect(recorded.RunType).Should(Equal("scheduled"))
+			Expect(recorded.Succeeded).Should(BeTrue())
+			Expect(*recorded.ExitCode).Should(Equal(int32(0)))
+			Expect(recorded.DurationSeconds).Should(BeNumerically("~", 90, 1))
+			tapID, targetID := string(batchv1alpha1.GetTapID(pwJob)), string(batchv1alpha1.GetTargetID(pwJob))
+			Expect(recorded.Tables).Should(Equal([]batchv1alpha1.TableStatistics{
+				{Name: "orders", Tap: tapID, Target: targetID, RowsExtracted: 15, RowsLoaded: 15},
+				{Name: "customers", Tap: tapID, Target: targetID, RowsExtracted: 0, RowsLoaded: 0},
+			}))
+
+			By("Exporting the run as metrics")
+			metricLabels := prometheus.Labels{
+				"namespace":   jobNamespace,
+				"job":         jobName,
+				"tap_type":    batchv1alpha1.GetTapConnectorID(pwJob),
+				"target_type": batchv1alpha1.GetTargetConnectorID(pwJob),
+			}
+			Expect(testutil.ToFloat64(jobRuns.With(withLabel(metricLabels, "result", "succeeded")))).Should(Equal(1.0))
+			Expect(testutil.ToFloat64(jobLastSuccess.With(metricLabels))).Should(Equal(float64(completionTime.Unix())))
+			Expect(testutil.ToFloat64(jobTableRowsLoaded.With(tableSeries{tap: tapID, target: targetID, table: "orders"}.labels(metricLabels)))).Should(Equal(15.0))
+			Expect(testutil.ToFloat64(jobSuspended.With(metricLabels))).Should(Equal(0.0))
+
+			By("Keeping only the latest runs")
+			failedRun := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-job-%v-2", jobName),
+					Namespace: jobNamespace,
+					Labels:    createdCronJob.Spec.JobTemplate.Labels,
+				},
+				Spec: *createdCronJob.Spec.JobTemplate.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, failedRun)).Should(Succeed())
+			failedTime := metav1.NewTime(completionTime.Add(time.Minute))
+			failedRun.Status = batchv1.JobStatus{
+				StartTime: &completionTime,
+				Failed:    1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobFailed,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: failedTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, failedRun)).Should(Succeed())
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || len(pwJob.Status.RecentRuns) == 0 {
+					return ""
+				}
+				return pwJob.Status.RecentRuns[0].JobName
+			}, timeout, interval).Should(Equal(failedRun.Name))
+			Expect(pwJob.Status.RecentRuns).Should(HaveLen(1))
+			Expect(pwJob.Status.RecentRuns[0].Succeeded).Should(BeFalse())
+			Expect(pwJob.Status.RecentRuns[0].ExitCode).Should(BeNil())
+			Expect(testutil.ToFloat64(jobRuns.With(withLabel(metricLabels, "result", "failed")))).Should(Equal(1.0))
+			Eventually(func() []string {
+				return eventReasons(ctx, pwJob, "")
+			}, timeout, interval).Should(ContainElements("ConfigRendered", "VolumeCreated", "CronJobCreated", "RunSucceeded", "RunFailed"))
+			Expect(testutil.ToFloat64(jobRuns.With(withLabel(metricLabels, "result", "succeeded")))).Should(Equal(1.0))
+			Expect(testutil.ToFloat64(jobTableRowsLoaded.With(tableSeries{tap: tapID, target: targetID, table: "orders"}.labels(metricLabels)))).Should(Equal(0.0))
+		})
+
+		It("Should notify webhooks about failed and recovered runs", func() {
+			ctx := context.Background()
+			jobName := "notifications"
+			received := make(chan map[string]interface{}, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				payload := map[string]interface{}{}
+				Expect(json.NewDecoder(req.Body).Decode(&payload)).Should(Succeed())
+				payload["path"] = req.URL.Path
+				received <- payload
+			}))
+			defer server.Close()
+
+			webhookSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "notification-webhooks",
+					Namespace: jobNamespace,
+				},
+				StringData: map[string]string{
+					"webhook": server.URL + "/webhook",
+					"slack":   server.URL + "/slack",
+				},
+			}
+			Expect(k8sClient.Create(ctx, webhookSecret)).Should(Succeed())
+			logTailLines := int32(2)
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Notifications: &batchv1alpha1.NotificationSpec{
+						LogTailLines: &logTailLines,
+						Receivers: []batchv1alpha1.NotificationReceiverSpec{
+							{
+								Type: batchv1alpha1.WebhookNotificationReceiver,
+								URLFrom: batchv1alpha1.ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: webhookSecret.Name},
+									Key:                  "webhook",
+								}},
+							},
+							{
+								Type: batchv1alpha1.SlackNotificationReceiver,
+								URLFrom: batchv1alpha1.ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: webhookSecret.Name},
+									Key:                  "slack",
+								}},
+							},
+						},
+					},
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdCronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0].TerminationMessagePolicy).Should(Equal(corev1.TerminationMessageFallbackToLogsOnError))
+
+			finishRun := func(suffix string, condition batchv1.JobConditionType, exitCode int32, message string) {
+				run := &batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("pw-job-%v-%v", jobName, suffix),
+						Namespace: jobNamespace,
+						Labels:    createdCronJob.Spec.JobTemplate.Labels,
+					},
+					Spec: *createdCronJob.Spec.JobTemplate.Spec.DeepCopy(),
+				}
+				Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+				runPod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%v-pod", run.Name),
+						Namespace: jobNamespace,
+						Labels:    map[string]string{"job-name": run.Name},
+					},
+					Spec: *run.Spec.Template.Spec.DeepCopy(),
+				}
+				Expect(k8sClient.Create(ctx, runPod)).Should(Succeed())
+				runPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+					{Name: "import", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+				}
+				runPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+					{Name: "runner", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message}}},
+				}
+				Expect(k8sClient.Status().Update(ctx, runPod)).Should(Succeed())
+				finishedTime := metav1.Now()
+				run.Status = batchv1.JobStatus{
+					StartTime: &finishedTime,
+					Conditions: []batchv1.JobCondition{
+						{
+							Type:               condition,
+							Status:             corev1.ConditionTrue,
+							LastTransitionTime: finishedTime,
+						},
+					},
+				}
+				Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+			}
+
+			By("Notifying a failed run with the failing container and the log tail")
+			finishRun("1", batchv1.JobFailed, 1, `{"tables":[],"logTail":"starting\nconnecting\nAccess denied for user"}`)
+			byPath := map[string]map[string]interface{}{}
+			for range pwJob.Spec.Notifications.Receivers {
+				payload := map[string]interface{}{}
+				Eventually(received, timeout, interval).Should(Receive(&payload))
+				byPath[payload["path"].(string)] = payload
+			}
+
+			webhook := byPath["/webhook"]
+			Expect(webhook["kind"]).Should(Equal("failure"))
+			Expect(webhook["tapId"]).Should(Equal(string(batchv1alpha1.GetTapID(pwJob))))
+			Expect(webhook["targetId"]).Should(Equal(string(batchv1alpha1.GetTargetID(pwJob))))
+			Expect(webhook["failedContainer"]).Should(Equal("runner"))
+			Expect(webhook["exitCode"]).Should(BeNumerically("==", 1))
+			Expect(webhook["logTail"]).Should(Equal("connecting\nAccess denied for user"))
+			Expect(byPath["/slack"]["text"]).Should(ContainSubstring("failed"))
+
+			By("Notifying the recovery with the next successful run")
+			finishRun("2", batchv1.JobComplete, 0, `{"tables":[]}`)
+			byPath = map[string]map[string]interface{}{}
+			for range pwJob.Spec.Notifications.Receivers {
+				payload := map[string]interface{}{}
+				Eventually(received, timeout, interval).Should(Receive(&payload))
+				byPath[payload["path"].(string)] = payload
+			}
+			Expect(byPath["/webhook"]["kind"]).Should(Equal("recovery"))
+			Expect(byPath["/webhook"]).ShouldNot(HaveKey("logTail"))
+			Expect(byPath["/slack"]["text"]).Should(ContainSubstring("recovered"))
+			Consistently(received, time.Second, interval).ShouldNot(Receive())
+		})
+
+		It("Should retry notifications until the receiver accepts them", func() {
+			ctx := context.Background()
+			jobName := "notification-retry"
+			received := make(chan map[string]interface{}, 10)
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				payload := map[string]interface{}{}
+				Expect(json.NewDecoder(req.Body).Decode(&payload)).Should(Succeed())
+				received <- payload
+			}))
+			defer server.Close()
+
+			webhookSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "notification-retry-webhook",
+					Namespace: jobNamespace,
+				},
+				StringData: map[string]string{
+					"webhook": server.URL,
+				},
+			}
+			Expect(k8sClient.Create(ctx, webhookSecret)).Should(Succeed())
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Notifications: &batchv1alpha1.NotificationSpec{
+						Receivers: []batchv1alpha1.NotificationReceiverSpec{
+							{
+								Type: batchv1alpha1.WebhookNotificationReceiver,
+								URLFrom: batchv1alpha1.ValueFromSource{SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: webhookSecret.Name},
+									Key:                  "webhook",
+								}},
+							},
+						},
+					},
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, &batchv1.CronJob{})
+			}, timeout, interval).Should(Succeed())
+
+			By("Recording a failed run while the receiver is unavailable")
+			run := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-job-%v-1", jobName),
+					Namespace: jobNamespace,
+					Labels: map[string]string{
+						"pwjob-name": jobName,
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "runner",
+									Image: "runner",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+			finishedTime := metav1.Now()
+			run.Status = batchv1.JobStatus{
+				StartTime: &finishedTime,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobFailed,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: finishedTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+
+			By("Notifying the run once the receiver accepts it")
+			payload := map[string]interface{}{}
+			Eventually(received, timeout, interval).Should(Receive(&payload))
+			Expect(payload["kind"]).Should(Equal("failure"))
+			Expect(payload["jobName"]).Should(Equal(run.Name))
+			Expect(atomic.LoadInt32(&requests)).Should(BeNumerically(">=", 2))
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || len(pwJob.Status.RecentRuns) != 1 {
+					return false
+				}
+				return !pwJob.Status.RecentRuns[0].NotificationPending
+			}, timeout, interval).Should(BeTrue())
+			Consistently(received, time.Second, interval).ShouldNot(Receive())
+		})
+
+		It("Should own generated resources and restore them when they drift", func() {
+			ctx := context.Background()
+			jobName := "owned-resources"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Setting controller owner references")
+			cronJobLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, cronJobLookupKey, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Expect(metav1.IsControlledBy(createdCronJob, pwJob)).Should(BeTrue())
+
+			createdConfig := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}, createdConfig)).Should(Succeed())
+			Expect(metav1.IsControlledBy(createdConfig, pwJob)).Should(BeTrue())
+
+			createdVolume := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-volume-%v", jobName), Namespace: jobNamespace}, createdVolume)).Should(Succeed())
+			Expect(metav1.IsControlledBy(createdVolume, pwJob)).Should(BeTrue())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: jobName, Namespace: jobNamespace}, pwJob)).Should(Succeed())
+			Expect(pwJob.Finalizers).Should(BeEmpty())
+
+			By("Recreating a deleted CronJob")
+			Expect(k8sClient.Delete(ctx, createdCronJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).Should(Succeed())
+			Eventually(func() bool {
+				recreatedCronJob := &batchv1.CronJob{}
+				if err := k8sClient.Get(ctx, cronJobLookupKey, recreatedCronJob); err != nil {
+					return false
+				}
+				return recreatedCronJob.UID != createdCronJob.UID
+			}, timeout, interval).Should(BeTrue())
+
+			By("Restoring an edited CronJob")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, cronJobLookupKey, createdCronJob); err != nil {
+					return err
+				}
+				createdCronJob.Spec.Schedule = "* * * * *"
+				return k8sClient.Update(ctx, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, cronJobLookupKey, createdCronJob); err != nil {
+					return ""
+				}
+				return createdCronJob.Spec.Schedule
+			}, timeout, interval).Should(Equal(cron))
+		})
+
+		It("Should switch between scheduled and continuous execution without sharing the state volume", func() {
+			ctx := context.Background()
+			jobName := "continuous"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			executorLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}
+			cronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, executorLookupKey, cronJob)
+			}, timeout, interval).Should(Succeed())
+			scheduledCondition := func() string {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return ""
+				}
+				condition := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled)
+				if condition == nil || condition.ObservedGeneration != pwJob.Generation {
+					return ""
+				}
+				return condition.Reason
+			}
+			// envtest runs no garbage collector, finish foreground deletions by hand
+			finishDeletion := func(obj client.Object) {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, executorLookupKey, obj); err != nil {
+						return err
+					}
+					if obj.GetDeletionTimestamp().IsZero() {
+						return fmt.Errorf("%v is not deleted yet", obj.GetName())
+					}
+					obj.SetFinalizers(nil)
+					return k8sClient.Update(ctx, obj)
+				}, timeout, interval).Should(Succeed())
+			}
+
+			By("Waiting for scheduled runs to stop before running continuously")
+			pauseSeconds := int32(30)
+			Expect(k8sClient.Get(ctx, pwJobLookupKey, pwJob)).Should(Succeed())
+			pwJob.Spec.Mode = batchv1alpha1.ContinuousExecutionMode
+			pwJob.Spec.Continuous = &batchv1alpha1.ContinuousSpec{PauseSeconds: &pauseSeconds}
+			Expect(k8sClient.Update(ctx, pwJob)).Should(Succeed())
+			Eventually(scheduledCondition, timeout, interval).Should(Equal("WaitingForRuns"))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, executorLookupKey, deployment)).Should(Succeed())
+			Expect(*deployment.Spec.Replicas).Should(BeZero())
+
+			finishDeletion(&batchv1.CronJob{})
+			Eventually(scheduledCondition, timeout, interval).Should(Equal("Running"))
+			Expect(k8sClient.Get(ctx, executorLookupKey, deployment)).Should(Succeed())
+			Expect(*deployment.Spec.Replicas).Should(BeEquivalentTo(1))
+			Expect(deployment.Spec.Strategy.Type).Should(Equal(appsv1.RecreateDeploymentStrategyType))
+			Expect(deployment.Spec.Template.Annotations).Should(HaveKey("pipelinewise.batch/config-hash"))
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.RestartPolicy).Should(Equal(corev1.RestartPolicyAlways))
+			Expect(podSpec.InitContainers[0].Args).Should(ContainElement("import"))
+			Expect(podSpec.Containers[0].Command).Should(ContainElement(ContainSubstring("sleep 30")))
+			Expect(podSpec.Containers[0].Args).Should(ContainElement("run_tap"))
+
+			By("Pausing the schedule until the continuous executor stopped")
+			pwJob.Spec.Mode = batchv1alpha1.ScheduledExecutionMode
+			Expect(k8sClient.Update(ctx, pwJob)).Should(Succeed())
+			Eventually(scheduledCondition, timeout, interval).Should(Equal("WaitingForRuns"))
+			Expect(k8sClient.Get(ctx, executorLookupKey, cronJob)).Should(Succeed())
+			Expect(*cronJob.Spec.Suspend).Should(BeTrue())
+
+			finishDeletion(&appsv1.Deployment{})
+			Eventually(scheduledCondition, timeout, interval).Should(Equal("CronJobReady"))
+			Expect(k8sClient.Get(ctx, executorLookupKey, cronJob)).Should(Succeed())
+			Expect(*cronJob.Spec.Suspend).Should(BeFalse())
+		})
+
+		It("Should edit the replication state of a continuous job once its pod is gone", func() {
+			ctx := context.Background()
+			jobName := "continuous-state-edit"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Mode:   batchv1alpha1.ContinuousExecutionMode,
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, &appsv1.Deployment{})
+			}, timeout, interval).Should(Succeed())
+
+			By("Keeping the state edit pending while a pod of the continuous executor is left")
+			// envtest runs no Deployment controller, the pod stands in for one still terminating
+			automountToken := false
+			continuousPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%v-pod", jobName),
+					Namespace: jobNamespace,
+					Labels: map[string]string{
+						"pwjob-name":                  jobName,
+						"pipelinewise.batch/run-type": "continuous",
+					},
+				},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: &automountToken,
+					Containers: []corev1.Container{
+						{Name: "runner", Image: "dimaspratama/pipelinewise:latest"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, continuousPod)).Should(Succeed())
+			Expect(k8sClient.Get(ctx, pwJobLookupKey, pwJob)).Should(Succeed())
+			pwJob.Spec.StateEdit = &batchv1alpha1.StateEditSpec{Token: "1", Reset: true}
+			Expect(k8sClient.Update(ctx, pwJob)).Should(Succeed())
+			stateEditPhase := func() batchv1alpha1.OperationPhase {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || pwJob.Status.LastStateEdit == nil {
+					return ""
+				}
+				return pwJob.Status.LastStateEdit.Phase
+			}
+			Eventually(stateEditPhase, timeout, interval).Should(Equal(batchv1alpha1.OperationPending))
+			Consistently(stateEditPhase, 2*time.Second, interval).Should(Equal(batchv1alpha1.OperationPending))
+
+			By("Starting the state edit once the pod is gone")
+			Expect(k8sClient.Delete(ctx, continuousPod)).Should(Succeed())
+			Eventually(stateEditPhase, timeout, interval).Should(Equal(batchv1alpha1.OperationRunning))
+		})
+
+		It("Should skip manual runs of a continuous job once", func() {
+			ctx := context.Background()
+			jobName := "continuous-run-now"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+					Annotations: map[string]string{
+						batchv1alpha1.RunNowAnnotation: "skipped",
+					},
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Mode:   batchv1alpha1.ContinuousExecutionMode,
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Consuming the token without a run")
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				_, pending := pwJob.Annotations[batchv1alpha1.RunNowAnnotation]
+				return !pending && pwJob.Status.LastManualRun != nil && pwJob.Status.LastManualRun.Token == "skipped"
+			}, timeout, interval).Should(BeTrue())
+			Expect(pwJob.Status.LastManualRun.Skipped).Should(BeTrue())
+			Expect(pwJob.Status.LastManualRun.JobName).Should(BeEmpty())
+
+			By("Not running the token once the job is scheduled again")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				pwJob.Spec.Mode = batchv1alpha1.ScheduledExecutionMode
+				pwJob.Spec.Schedule = cron
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Consistently(func() ([]batchv1.Job, error) {
+				runs := &batchv1.JobList{}
+				err := k8sClient.List(ctx, runs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName, "pipelinewise.batch/run-type": "manual"})
+				return runs.Items, err
+			}, time.Second*2, interval).Should(BeEmpty())
+		})
+
+		It("Should run a job on demand without overlapping other runs", func() {
+			ctx := context.Background()
+			jobName := "run-now"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+					Annotations: map[string]string{
+						batchv1alpha1.RunNowAnnotation: "first",
+					},
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			cronJobLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}
+			manualRuns := func() []batchv1.Job {
+				runs := &batchv1.JobList{}
+				if err := k8sClient.List(ctx, runs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName, "pipelinewise.batch/run-type": "manual"}); err != nil {
+					return nil
+				}
+				return runs.Items
+			}
+
+			By("Creating a one-off Job and consuming the token")
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				_, pending := pwJob.Annotations[batchv1alpha1.RunNowAnnotation]
+				return !pending && pwJob.Status.LastManualRun != nil && pwJob.Status.LastManualRun.Token == "first"
+			}, timeout, interval).Should(BeTrue())
+			Expect(manualRuns()).Should(HaveLen(1))
+			firstRun := manualRuns()[0]
+			Expect(firstRun.Name).Should(Equal(pwJob.Status.LastManualRun.JobName))
+			Expect(metav1.IsControlledBy(&firstRun, pwJob)).Should(BeTrue())
+			Expect(firstRun.Spec.Template.Spec.Containers[0].Name).Should(Equal("runner"))
+			Expect(pwJob.Status.Active).Should(ContainElement(firstRun.Name))
+			Expect(meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled).Reason).Should(Equal("ManualRunActive"))
+
+			By("Pausing the schedule while the manual run is active")
+			createdCronJob := &batchv1.CronJob{}
+			Expect(k8sClient.Get(ctx, cronJobLookupKey, createdCronJob)).Should(Succeed())
+			Expect(createdCronJob.Spec.Suspend).ShouldNot(BeNil())
+			Expect(*createdCronJob.Spec.Suspend).Should(BeTrue())
+
+			By("Deferring another token while a run is active")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				pwJob.Annotations = map[string]string{batchv1alpha1.RunNowAnnotation: "second"}
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Consistently(func() int {
+				return len(manualRuns())
+			}, time.Second*2, interval).Should(Equal(1))
+			Expect(k8sClient.Get(ctx, pwJobLookupKey, pwJob)).Should(Succeed())
+			Expect(pwJob.Annotations).Should(HaveKeyWithValue(batchv1alpha1.RunNowAnnotation, "second"))
+
+			By("Starting the deferred run once the active run finishes")
+			completionTime := metav1.Now()
+			firstRun.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, &firstRun)).Should(Succeed())
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				_, pending := pwJob.Annotations[batchv1alpha1.RunNowAnnotation]
+				return !pending && pwJob.Status.LastManualRun != nil && pwJob.Status.LastManualRun.Token == "second"
+			}, timeout, interval).Should(BeTrue())
+			Expect(manualRuns()).Should(HaveLen(2))
+		})
+
+		It("Should pause the schedule while a manual run waits for a scheduled run", func() {
+			ctx := context.Background()
+			jobName := "run-now-waiting"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			cronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)
+			}, timeout, interval).Should(Succeed())
+			manualRuns := func() []batchv1.Job {
+				runs := &batchv1.JobList{}
+				if err := k8sClient.List(ctx, runs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName, "pipelinewise.batch/run-type": "manual"}); err != nil {
+					return nil
+				}
+				return runs.Items
+			}
+
+			By("Starting a scheduled run")
+			isController := true
+			scheduledRun := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%v-1", cronJob.Name),
+					Namespace: jobNamespace,
+					Labels: map[string]string{
+						"pwjob-name": jobName,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "batch/v1",
+							Kind:       "CronJob",
+							Name:       cronJob.Name,
+							UID:        cronJob.UID,
+							Controller: &isController,
+						},
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "runner",
+									Image: "runner",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, scheduledRun)).Should(Succeed())
+
+			By("Pausing the schedule as soon as the token arrives")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				pwJob.Annotations = map[string]string{batchv1alpha1.RunNowAnnotation: "waiting"}
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() (bool, error) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cronJob), cronJob)
+				return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend, err
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() (string, error) {
+				err := k8sClient.Get(ctx, pwJobLookupKey, pwJob)
+				if scheduled := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled); scheduled != nil {
+					return scheduled.Reason, err
+				}
+				return "", err
+			}, timeout, interval).Should(Equal("ManualRunPending"))
+			Expect(manualRuns()).Should(BeEmpty())
+			metricLabels := prometheus.Labels{
+				"namespace":   jobNamespace,
+				"job":         jobName,
+				"tap_type":    batchv1alpha1.GetTapConnectorID(pwJob),
+				"target_type": batchv1alpha1.GetTargetConnectorID(pwJob),
+			}
+			Eventually(func() float64 {
+				return testutil.ToFloat64(jobSuspended.With(metricLabels))
+			}, timeout, interval).Should(Equal(1.0))
+
+			By("Starting the manual run once the scheduled run finished")
+			completionTime := metav1.Now()
+			scheduledRun.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, scheduledRun)).Should(Succeed())
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				return pwJob.Status.LastManualRun != nil && pwJob.Status.LastManualRun.Token == "waiting"
+			}, timeout, interval).Should(BeTrue())
+			Expect(manualRuns()).Should(HaveLen(1))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cronJob), cronJob)).Should(Succeed())
+			Expect(*cronJob.Spec.Suspend).Should(BeTrue())
+		})
+
+		It("Should edit the replication state while the schedule is paused", func() {
+			ctx := context.Background()
+			jobName := "state-edit"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					StateEdit: &batchv1alpha1.StateEditSpec{
+						Token: "1",
+						Streams: []batchv1alpha1.StreamBookmarkSpec{
+							{
+								Stream:   "default-source-default-table",
+								Bookmark: &apiextensionsv1.JSON{Raw: []byte(`{"log_file":"mysql-bin.000003","log_pos":4}`)},
+							},
+						},
+					},
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Running the state editor against the state volume")
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() batchv1alpha1.OperationPhase {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || pwJob.Status.LastStateEdit == nil {
+					return ""
+				}
+				return pwJob.Status.LastStateEdit.Phase
+			}, timeout, interval).Should(Equal(batchv1alpha1.OperationRunning))
+			Expect(meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled).Reason).Should(Equal("StateEditActive"))
+
+			statePath := fmt.Sprintf("/root/.pipelinewise/%v/%v/state.json", batchv1alpha1.GetTargetID(pwJob), batchv1alpha1.GetTapID(pwJob))
+			backupPath := fmt.Sprintf("%v.%v.bak", statePath, pwJob.Status.LastStateEdit.JobName)
+			Expect(pwJob.Status.LastStateEdit.BackupPath).Should(Equal(backupPath))
+
+			stateEditRun := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pwJob.Status.LastStateEdit.JobName, Namespace: jobNamespace}, stateEditRun)).Should(Succeed())
+			Expect(stateEditRun.Labels).Should(HaveKeyWithValue("pipelinewise.batch/run-type", "state-edit"))
+			Expect(stateEditRun.Spec.Template.Spec.InitContainers).Should(BeEmpty())
+			runner := stateEditRun.Spec.Template.Spec.Containers[0]
+			Expect(runner.Command[0]).Should(Equal("python3"))
+			Expect(runner.Args).Should(Equal([]string{statePath, backupPath}))
+			Expect(runner.Env).Should(ContainElement(corev1.EnvVar{
+				Name:  "PIPELINEWISE_STATE_EDIT",
+				Value: `{"token":"1","streams":[{"stream":"default-source-default-table","bookmark":{"log_file":"mysql-bin.000003","log_pos":4}}]}`,
+			}))
+			Expect(runner.VolumeMounts).Should(ContainElement(corev1.VolumeMount{Name: "runtime-volume", MountPath: "/root/.pipelinewise"}))
+
+			cronJob := &batchv1.CronJob{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)).Should(Succeed())
+			Expect(*cronJob.Spec.Suspend).Should(BeTrue())
+
+			By("Reporting the state edit outcome without counting it as a run")
+			completionTime := metav1.Now()
+			stateEditRun.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, stateEditRun)).Should(Succeed())
+			Eventually(func() batchv1alpha1.OperationPhase {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || pwJob.Status.LastStateEdit == nil {
+					return ""
+				}
+				return pwJob.Status.LastStateEdit.Phase
+			}, timeout, interval).Should(Equal(batchv1alpha1.OperationSucceeded))
+			Expect(pwJob.Status.LastSuccessfulTime).Should(BeNil())
+			Expect(meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionLastRunSucceeded).Reason).Should(Equal("NoRunYet"))
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob); err != nil {
+					return false
+				}
+				return !*cronJob.Spec.Suspend
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should resync tables while the schedule is paused", func() {
+			ctx := context.Background()
+			jobName := "resync"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Resync: &batchv1alpha1.ResyncSpec{
+						Token:  "1",
+						Tables: []string{"default-source.default-table"},
+					},
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Running sync_tables for the requested tables")
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() batchv1alpha1.OperationPhase {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || pwJob.Status.LastResync == nil {
+					return ""
+				}
+				return pwJob.Status.LastResync.Phase
+			}, timeout, interval).Should(Equal(batchv1alpha1.OperationRunning))
+			Expect(meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled).Reason).Should(Equal("ResyncActive"))
+
+			resyncRun := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pwJob.Status.LastResync.JobName, Namespace: jobNamespace}, resyncRun)).Should(Succeed())
+			Expect(resyncRun.Labels).Should(HaveKeyWithValue("pipelinewise.batch/run-type", "resync"))
+			Expect(resyncRun.Spec.Template.Spec.InitContainers[0].Args).Should(ContainElement("import"))
+			Expect(resyncRun.Spec.Template.Spec.Containers[0].Args).Should(Equal([]string{
+				"sync_tables", "--tap", string(batchv1alpha1.GetTapID(pwJob)), "--target", string(batchv1alpha1.GetTargetID(pwJob)), "--tables", "default-source.default-table",
+			}))
+
+			cronJob := &batchv1.CronJob{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)).Should(Succeed())
+			Expect(*cronJob.Spec.Suspend).Should(BeTrue())
+
+			By("Reporting the resync outcome and resuming the schedule")
+			completionTime := metav1.Now()
+			resyncRun.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, resyncRun)).Should(Succeed())
+			Eventually(func() batchv1alpha1.OperationPhase {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil || pwJob.Status.LastResync == nil {
+					return ""
+				}
+				return pwJob.Status.LastResync.Phase
+			}, timeout, interval).Should(Equal(batchv1alpha1.OperationSucceeded))
+			Expect(pwJob.Status.LastResync.CompletionTime).ShouldNot(BeNil())
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob); err != nil {
+					return false
+				}
+				return !*cronJob.Spec.Suspend
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should inject credentials referenced from a Secret into the import container", func() {
+			ctx := context.Background()
+			jobName := "secret-refs"
+			passwordRef := &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "postgres-credentials"},
+				Key:                  "password",
+			}
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target: batchv1alpha1.TargetSpec{
+						PostgreSQL: &batchv1alpha1.PostgreSQLTargetSpec{
+							Host:         "postgres-host",
+							User:         "postgres-user",
+							PasswordFrom: &batchv1alpha1.ValueFromSource{SecretKeyRef: passwordRef},
+							DatabaseName: "postgres-db",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering an environment variable lookup instead of the value")
+			createdConfig := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}, createdConfig)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("target_postgres-postgres-db.yaml", ContainSubstring(`password: '{{ env_var["PW_TARGET_PASSWORD"]`)))
+			Expect(string(createdConfig.Data["target_postgres-postgres-db.yaml"])).ShouldNot(ContainSubstring("password_from"))
+
+			By("Injecting the Secret key into the import container")
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			importContainer := createdCronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0]
+			Expect(importContainer.Env).Should(ContainElement(corev1.EnvVar{
+				Name:      "PW_TARGET_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordRef},
+			}))
+		})
+
+		It("Should render the main configuration of the job or the operator", func() {
+			ctx := context.Background()
+			tokenRef := &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "pipelinewise-alerts"},
+				Key:                  "slack-token",
+			}
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "main-config",
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					MainConfig: &batchv1alpha1.MainConfigSpec{
+						AlertHandlers: &batchv1alpha1.AlertHandlersSpec{
+							Slack: &batchv1alpha1.SlackAlertHandlerSpec{
+								TokenFrom: &batchv1alpha1.ValueFromSource{SecretKeyRef: tokenRef},
+								Channel:   "#alerts",
+							},
+						},
+						Logging: "[loggers]\nkeys=root\n",
+					},
+					Tap:    defaultTapSpec,
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering alert handlers and logging next to the tap and target")
+			createdConfig := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "pw-config-main-config", Namespace: jobNamespace}, createdConfig)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("config.yml", ContainSubstring(`token: '{{ env_var["PW_CONFIG_SLACK_TOKEN"]`)))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("config.yml", ContainSubstring("channel: '#alerts'")))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("logging.conf", BeEquivalentTo("[loggers]\nkeys=root\n")))
+
+			By("Pointing import and run_tap to the logging configuration")
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "pw-job-main-config", Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			loggingEnv := corev1.EnvVar{Name: "LOGGING_CONF_FILE", Value: "/configurations/logging.conf"}
+			podSpec := createdCronJob.Spec.JobTemplate.Spec.Template.Spec
+			Expect(podSpec.InitContainers[0].Env).Should(ContainElement(loggingEnv))
+			Expect(podSpec.InitContainers[0].Env).Should(ContainElement(corev1.EnvVar{
+				Name:      "PW_CONFIG_SLACK_TOKEN",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: tokenRef},
+			}))
+			Expect(podSpec.Containers[0].Env).Should(ContainElement(loggingEnv))
+
+			By("Falling back to the operator ConfigMap")
+			viper.Set(mainConfigMapSetting, fmt.Sprintf("%v/pipelinewise-main-config", jobNamespace))
+			defer viper.Set(mainConfigMapSetting, "")
+			operatorConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pipelinewise-main-config",
+					Namespace: jobNamespace,
+				},
+				Data: map[string]string{
+					"config.yaml": "alertHandlers:\n  victorops:\n    base_url: https://alert.victorops.com/integrations/generic/1/alert/key\n    routing_key: data\n",
+				},
+			}
+			Expect(k8sClient.Create(ctx, operatorConfig)).Should(Succeed())
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: pwJob.Name, Namespace: jobNamespace}, pwJob); err != nil {
+					return err
+				}
+				pwJob.Spec.MainConfig = nil
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() (map[string][]byte, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "pw-config-main-config", Namespace: jobNamespace}, createdConfig)
+				return createdConfig.Data, err
+			}, timeout, interval).Should(HaveKeyWithValue("config.yml", ContainSubstring("routing_key: data")))
+			Expect(createdConfig.Data).ShouldNot(HaveKey("logging.conf"))
+
+			By("Re-rendering once the operator ConfigMap changes")
+			operatorConfig.Data["config.yaml"] = strings.ReplaceAll(operatorConfig.Data["config.yaml"], "routing_key: data", "routing_key: platform")
+			Expect(k8sClient.Update(ctx, operatorConfig)).Should(Succeed())
+			Eventually(func() (map[string][]byte, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "pw-config-main-config", Namespace: jobNamespace}, createdConfig)
+				return createdConfig.Data, err
+			}, timeout, interval).Should(HaveKeyWithValue("config.yml", ContainSubstring("routing_key: platform")))
+		})
+
+		It("Should render referenced targets and keep them while referenced", func() {
+			ctx := context.Background()
+			jobName := "target-ref"
+			sharedTarget := &batchv1alpha1.PipelinewiseTarget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "shared-warehouse",
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseTargetSpec{
+					TargetSpec: batchv1alpha1.TargetSpec{
+						PostgreSQL: &batchv1alpha1.PostgreSQLTargetSpec{
+							Host:         "warehouse",
+							User:         "loader",
+							Password:     "loader-password",
+							DatabaseName: "warehouse",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, sharedTarget)).Should(Succeed())
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule:  cron,
+					Tap:       defaultTapSpec,
+					TargetRef: &corev1.LocalObjectReference{Name: sharedTarget.Name},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering the referenced target")
+			configID := types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}
+			createdConfig := &corev1.Secret{}
+			Eventually(func() (map[string][]byte, error) {
+				err := k8sClient.Get(ctx, configID, createdConfig)
+				return createdConfig.Data, err
+			}, timeout, interval).Should(HaveKeyWithValue("target_postgres-warehouse.yaml", ContainSubstring("host: warehouse")))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue(MatchRegexp("^tap_"), ContainSubstring("target: postgres-warehouse")))
+			Eventually(func() ([]string, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: sharedTarget.Name, Namespace: jobNamespace}, sharedTarget)
+				return sharedTarget.Status.ReferencedBy, err
+			}, timeout, interval).Should(Equal([]string{jobName}))
+			Expect(sharedTarget.Finalizers).Should(ContainElement("pipelinewise.batch/in-use"))
+
+			By("Keeping the reference in the job spec")
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: jobName, Namespace: jobNamespace}, pwJob)).Should(Succeed())
+			Expect(pwJob.Spec.Target.PostgreSQL).Should(BeNil())
+
+			By("Re-rendering the job once the shared target changes")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: sharedTarget.Name, Namespace: jobNamespace}, sharedTarget); err != nil {
+					return err
+				}
+				sharedTarget.Spec.PostgreSQL.Host = "warehouse-replica"
+				return k8sClient.Update(ctx, sharedTarget)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() (map[string][]byte, error) {
+				err := k8sClient.Get(ctx, configID, createdConfig)
+				return createdConfig.Data, err
+			}, timeout, interval).Should(HaveKeyWithValue("target_postgres-warehouse.yaml", ContainSubstring("host: warehouse-replica")))
+
+			By("Blocking deletion of the target while the job references it")
+			Expect(k8sClient.Delete(ctx, sharedTarget)).Should(Succeed())
+			Consistently(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: sharedTarget.Name, Namespace: jobNamespace}, sharedTarget)
+			}, time.Second, interval).Should(Succeed())
+			Expect(sharedTarget.DeletionTimestamp).ShouldNot(BeNil())
+
+			By("Deleting the target once the job is gone")
+			Expect(k8sClient.Delete(ctx, pwJob)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: sharedTarget.Name, Namespace: jobNamespace}, sharedTarget)
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should run several taps one after the other and on their own schedules", func() {
+			ctx := context.Background()
+			jobName := "several-taps"
+			billingTap := *defaultTapSpec.DeepCopy()
+			billingTap.MySQL.Connection.DBName = "billing"
+			crmTap := *defaultTapSpec.DeepCopy()
+			crmTap.MySQL.Connection.DBName = "crm"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Taps: []batchv1alpha1.JobTapSpec{
+						{TapSpec: defaultTapSpec},
+						{TapSpec: billingTap, Schedule: "*/30 * * * *"},
+						{TapSpec: crmTap},
+					},
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering every tap into its own file")
+			createdConfig := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}, createdConfig)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdConfig.Data).Should(HaveKey("tap_mysql-default-db-name.yaml"))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("tap_mysql-billing.yaml", ContainSubstring("dbname: billing")))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("tap_mysql-crm.yaml", ContainSubstring("target: postgres-")))
+			Expect(createdConfig.Data).Should(HaveKey(MatchRegexp("^target_postgres-")))
+
+			By("Running the taps without schedule one after the other on the job schedule")
+			cronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)
+			}, timeout, interval).Should(Succeed())
+			podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+			containerNames := func(containers []corev1.Container) []string {
+				names := []string{}
+				for _, container := range containers {
+					names = append(names, container.Name)
+				}
+				return names
+			}
+			Expect(containerNames(podSpec.InitContainers)).Should(Equal([]string{"import-mysql-default-db-name", "runner-mysql-default-db-name", "import-mysql-crm"}))
+			Expect(containerNames(podSpec.Containers)).Should(Equal([]string{"runner-mysql-crm"}))
+			Expect(podSpec.Containers[0].Args).Should(ContainElement("mysql-crm"))
+			Expect(podSpec.InitContainers[2].VolumeMounts).Should(ContainElement(corev1.VolumeMount{
+				Name:      "pipelinewise-configuration",
+				MountPath: "/configurations/tap_mysql-crm.yaml",
+				SubPath:   "tap_mysql-crm.yaml",
+			}))
+			for _, volumeMount := range podSpec.InitContainers[2].VolumeMounts {
+				Expect(volumeMount.SubPath).ShouldNot(Equal("tap_mysql-default-db-name.yaml"))
+			}
+
+			By("Running the tap with a schedule from its own CronJob")
+			tapCronJobs := &batchv1.CronJobList{}
+			Eventually(func() ([]batchv1.CronJob, error) {
+				err := k8sClient.List(ctx, tapCronJobs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName})
+				return tapCronJobs.Items, err
+			}, timeout, interval).Should(HaveLen(1))
+			tapCronJob := tapCronJobs.Items[0]
+			Expect(tapCronJob.Spec.Schedule).Should(Equal("*/30 * * * *"))
+			Expect(tapCronJob.Spec.JobTemplate.Annotations).Should(HaveKeyWithValue("pipelinewise.batch/tap-id", "mysql-billing"))
+			Expect(containerNames(tapCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers)).Should(Equal([]string{"runner"}))
+			Expect(tapCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).Should(ContainElement("mysql-billing"))
+
+			By("Removing the CronJob of the tap once it runs on the job schedule again")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: jobName, Namespace: jobNamespace}, pwJob); err != nil {
+					return err
+				}
+				pwJob.Spec.Taps[1].Schedule = ""
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&tapCronJob), &tapCronJob)
+				return errors.IsNotFound(err) || !tapCronJob.DeletionTimestamp.IsZero()
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() ([]string, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)
+				return containerNames(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers), err
+			}, timeout, interval).Should(Equal([]string{"runner-mysql-crm"}))
+			Expect(containerNames(cronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers)).Should(ContainElement("runner-mysql-billing"))
+		})
+
+		It("Should not overlap runs of taps on their own schedules", func() {
+			ctx := context.Background()
+			jobName := "overlapping-taps"
+			billingTap := *defaultTapSpec.DeepCopy()
+			billingTap.MySQL.Connection.DBName = "billing"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Taps: []batchv1alpha1.JobTapSpec{
+						{TapSpec: defaultTapSpec, Schedule: "*/30 * * * *"},
+						{TapSpec: billingTap, Schedule: "*/15 * * * *"},
+					},
+					Target: defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			tapCronJobs := &batchv1.CronJobList{}
+			Eventually(func() ([]batchv1.CronJob, error) {
+				err := k8sClient.List(ctx, tapCronJobs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName})
+				return tapCronJobs.Items, err
+			}, timeout, interval).Should(HaveLen(2))
+			var defaultCronJob, billingCronJob batchv1.CronJob
+			for _, tapCronJob := range tapCronJobs.Items {
+				Expect(tapCronJob.Spec.Suspend == nil || !*tapCronJob.Spec.Suspend).Should(BeTrue())
+				if tapCronJob.Spec.Schedule == "*/30 * * * *" {
+					defaultCronJob = tapCronJob
+				} else {
+					billingCronJob = tapCronJob
+				}
+			}
+			isSuspended := func(cronJob *batchv1.CronJob) func() (bool, error) {
+				return func() (bool, error) {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cronJob), cronJob)
+					return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend, err
+				}
+			}
+
+			By("Pausing the other CronJob while the run of the first tap is active")
+			isController := true
+			run := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%v-1", defaultCronJob.Name),
+					Namespace: jobNamespace,
+					Labels: map[string]string{
+						"pwjob-name": jobName,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "batch/v1",
+							Kind:       "CronJob",
+							Name:       defaultCronJob.Name,
+							UID:        defaultCronJob.UID,
+							Controller: &isController,
+						},
+					},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:  "runner",
+									Image: "runner",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, run)).Should(Succeed())
+			Eventually(isSuspended(&billingCronJob), timeout, interval).Should(BeTrue())
+			Expect(isSuspended(&defaultCronJob)()).Should(BeFalse())
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() (string, error) {
+				err := k8sClient.Get(ctx, pwJobLookupKey, pwJob)
+				if scheduled := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionScheduled); scheduled != nil {
+					return scheduled.Reason, err
+				}
+				return "", err
+			}, timeout, interval).Should(Equal("WaitingForRuns"))
+
+			By("Resuming the other CronJob once the run finished")
+			completionTime := metav1.Now()
+			run.Status = batchv1.JobStatus{
+				StartTime:      &completionTime,
+				CompletionTime: &completionTime,
+				Succeeded:      1,
+				Conditions: []batchv1.JobCondition{
+					{
+						Type:               batchv1.JobComplete,
+						Status:             corev1.ConditionTrue,
+						LastTransitionTime: completionTime,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, run)).Should(Succeed())
+			Eventually(isSuspended(&billingCronJob), timeout, interval).Should(BeFalse())
+		})
+
+		It("Should load a tap into several targets with a state of its own for each target", func() {
+			ctx := context.Background()
+			jobName := "several-targets"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Targets: []batchv1alpha1.JobTargetSpec{
+						{TargetSpec: defaultTargetSpec},
+						{
+							TargetSpec: batchv1alpha1.TargetSpec{
+								S3CSV: &batchv1alpha1.S3CSVTargetSpec{
+									S3Bucket: "archive",
+								},
+							},
+							Schedule: "0 3 * * *",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering a copy of the tap for every target")
+			createdConfig := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}, createdConfig)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("tap_mysql-default-db-name-postgres-.yaml", ContainSubstring("target: postgres-\n")))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("tap_mysql-default-db-name-s3-csv-archive.yaml", ContainSubstring("id: mysql-default-db-name-s3-csv-archive")))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("tap_mysql-default-db-name-s3-csv-archive.yaml", ContainSubstring("target: s3-csv-archive")))
+			Expect(createdConfig.Data).Should(HaveKey("target_postgres-.yaml"))
+			Expect(createdConfig.Data).Should(HaveKeyWithValue("target_s3-csv-archive.yaml", ContainSubstring("s3_bucket: archive")))
+
+			By("Loading the targets without schedule on the job schedule")
+			cronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, cronJob)
+			}, timeout, interval).Should(Succeed())
+			podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+			Expect(podSpec.Containers).Should(HaveLen(1))
+			Expect(podSpec.Containers[0].Args).Should(ContainElement("mysql-default-db-name-postgres-"))
+			Expect(podSpec.Containers[0].Args).Should(ContainElement("postgres-"))
+
+			By("Loading the target with a schedule from its own CronJob")
+			targetCronJobs := &batchv1.CronJobList{}
+			Eventually(func() ([]batchv1.CronJob, error) {
+				err := k8sClient.List(ctx, targetCronJobs, client.InNamespace(jobNamespace), client.MatchingLabels{"pwjob-name": jobName})
+				return targetCronJobs.Items, err
+			}, timeout, interval).Should(HaveLen(1))
+			targetCronJob := targetCronJobs.Items[0]
+			Expect(targetCronJob.Spec.Schedule).Should(Equal("0 3 * * *"))
+			Expect(targetCronJob.Spec.JobTemplate.Annotations).Should(HaveKeyWithValue("pipelinewise.batch/tap-id", "mysql-default-db-name-s3-csv-archive"))
+			Expect(targetCronJob.Spec.JobTemplate.Annotations).Should(HaveKeyWithValue("pipelinewise.batch/target-id", "s3-csv-archive"))
+			Expect(targetCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).Should(ContainElement("s3-csv-archive"))
+			Expect(targetCronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0].VolumeMounts).Should(ContainElement(corev1.VolumeMount{
+				Name:      "pipelinewise-configuration",
+				MountPath: "/configurations/target_s3-csv-archive.yaml",
+				SubPath:   "target_s3-csv-archive.yaml",
+			}))
+		})
+
+		It("Should remove the configuration script of earlier operator versions", func() {
+			ctx := context.Background()
+			jobName := "legacy-config-script"
+			configScript := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pw-config-script",
+					Namespace: jobNamespace,
+				},
+				Data: map[string]string{
+					"configuration-mod.sh": "#!/bin/sh",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configScript)).Should(Succeed())
+
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(configScript), configScript)
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should label the configuration of earlier operator versions", func() {
+			ctx := context.Background()
+			jobName := "unlabeled-config"
+			// Earlier operator versions did not label the rendered configuration, so the cache of the manager misses it
+			legacyConfig := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pw-config-%v", jobName),
+					Namespace: jobNamespace,
+				},
+				StringData: map[string]string{
+					"tap.yaml": "id: stale",
+				},
+			}
+			Expect(k8sClient.Create(ctx, legacyConfig)).Should(Succeed())
+
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			Eventually(func() (map[string]string, error) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(legacyConfig), legacyConfig)
+				return legacyConfig.Labels, err
+			}, timeout, interval).Should(HaveKeyWithValue("pwjob-name", jobName))
+			Expect(metav1.IsControlledBy(legacyConfig, pwJob)).Should(BeTrue())
+			Expect(legacyConfig.Data).ShouldNot(HaveKey("tap.yaml"))
+
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("Should report a degraded job while the referenced tap is missing", func() {
+			ctx := context.Background()
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "missing-tap-ref",
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					TapRef:   &corev1.LocalObjectReference{Name: "orders-db"},
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+			Eventually(func() (string, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: pwJob.Name, Namespace: jobNamespace}, pwJob)
+				degraded := meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionDegraded)
+				if degraded == nil {
+					return "", err
+				}
+				return degraded.Reason, err
+			}, timeout, interval).Should(Equal("ReferenceFailed"))
+
+			By("Rendering the tap once it is created")
+			sharedTap := &batchv1alpha1.PipelinewiseTap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "orders-db",
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseTapSpec{TapSpec: *defaultTapSpec.DeepCopy()},
+			}
+			Expect(k8sClient.Create(ctx, sharedTap)).Should(Succeed())
+			Eventually(func() (metav1.ConditionStatus, error) {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: pwJob.Name, Namespace: jobNamespace}, pwJob)
+				return meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionConfigRendered).Status, err
+			}, timeout, interval).Should(Equal(metav1.ConditionTrue))
+		})
+
+		It("Should encrypt sensitive values with the master password", func() {
+			ctx := context.Background()
+			jobName := "encrypted"
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "encrypted-credentials",
+					Namespace: jobNamespace,
+				},
+				StringData: map[string]string{
+					"master-password": "master",
+					"mysql-password":  "referenced-password",
+				},
+			}
+			Expect(k8sClient.Create(ctx, credentials)).Should(Succeed())
+
+			tap := defaultTapSpec.DeepCopy()
+			tap.MySQL.Connection.PasswordFrom = &batchv1alpha1.ValueFromSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: credentials.Name},
+					Key:                  "mysql-password",
+				},
+			}
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      *tap,
+					Target:   defaultTargetSpec,
+					Secret: &batchv1alpha1.SecretSpec{
+						Name: credentials.Name,
+						Key:  "master-password",
+					},
+					Encrypted: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Rendering encrypted strings instead of plaintext and references")
+			createdConfig := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}, createdConfig)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdConfig.Data).Should(HaveLen(2))
+			for _, config := range createdConfig.Data {
+				Expect(string(config)).Should(MatchRegexp(`password: !vault \|\n\s+\$ANSIBLE_VAULT;1\.1;AES256\n`))
+				Expect(string(config)).ShouldNot(ContainSubstring("password_from"))
+				Expect(string(config)).ShouldNot(ContainSubstring("env_var"))
+				Expect(string(config)).ShouldNot(ContainSubstring("referenced-password"))
+				Expect(string(config)).ShouldNot(ContainSubstring("ordinary-password"))
+			}
+
+			By("Keeping referenced values out of the import container")
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdCronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0].Env).Should(BeEmpty())
+
+			By("Importing with the master password")
+			importContainer := createdCronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0]
+			Expect(importContainer.Command).Should(BeEmpty())
+			Expect(importContainer.Args).Should(Equal([]string{"import", "--dir", "/configurations", "--secret", "/secrets/master-password"}))
+			Expect(importContainer.VolumeMounts).Should(ContainElement(corev1.VolumeMount{Name: "pw-master-password", MountPath: "/secrets"}))
+		})
+
+		It("Should merge pod template overrides into the executor pod", func() {
+			ctx := context.Background()
+			jobName := "pod-template"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+					PodTemplate: &batchv1alpha1.PodTemplateSpec{
+						Annotations:        map[string]string{"cluster-autoscaler.kubernetes.io/safe-to-evict": "false"},
+						NodeSelector:       map[string]string{"pool": "data"},
+						Tolerations:        []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "data", Effect: corev1.TaintEffectNoSchedule}},
+						ServiceAccountName: "pipelinewise",
+						ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry"}},
+						Container: &batchv1alpha1.ContainerOverrideSpec{
+							Resources: &corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+							},
+							Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+						},
+						Runner: &batchv1alpha1.ContainerOverrideSpec{
+							Resources: &corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("4Gi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("4Gi"),
+								},
+							},
+							Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			pod := createdCronJob.Spec.JobTemplate.Spec.Template
+
+			By("Applying pod level settings")
+			Expect(pod.Annotations).Should(HaveKeyWithValue("cluster-autoscaler.kubernetes.io/safe-to-evict", "false"))
+			Expect(pod.Spec.NodeSelector).Should(HaveKeyWithValue("pool", "data"))
+			Expect(pod.Spec.Tolerations).Should(HaveLen(1))
+			Expect(pod.Spec.ServiceAccountName).Should(Equal("pipelinewise"))
+			Expect(pod.Spec.ImagePullSecrets).Should(ContainElement(corev1.LocalObjectReference{Name: "registry"}))
+
+			By("Applying shared container settings to the import container")
+			importContainer := pod.Spec.InitContainers[0]
+			Expect(importContainer.Resources.Requests.Memory().String()).Should(Equal("256Mi"))
+			Expect(importContainer.Resources.Requests.Cpu().String()).Should(Equal("100m"))
+			Expect(importContainer.Env).Should(ContainElement(corev1.EnvVar{Name: "LOG_LEVEL", Value: "info"}))
+
+			By("Applying runner settings on top of shared container settings")
+			runnerContainer := pod.Spec.Containers[0]
+			Expect(runnerContainer.Resources.Requests.Memory().String()).Should(Equal("4Gi"))
+			Expect(runnerContainer.Resources.Requests.Cpu().String()).Should(Equal("100m"))
+			Expect(runnerContainer.Resources.Limits.Memory().String()).Should(Equal("4Gi"))
+			Expect(runnerContainer.Env).Should(Equal([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}))
+		})
+
+		It("Should configure, expand and retain the state volume", func() {
+			ctx := context.Background()
+			jobName := "state-volume"
+			allowVolumeExpansion := true
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "expandable",
+				},
+				Provisioner:          "kubernetes.io/no-provisioner",
+				AllowVolumeExpansion: &allowVolumeExpansion,
+			}
+			Expect(k8sClient.Create(ctx, storageClass)).Should(Succeed())
+
+			size := resource.MustParse("2Gi")
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+					Volume: &batchv1alpha1.VolumeSpec{
+						Size:             &size,
+						StorageClassName: &storageClass.Name,
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+						RetainOnDelete:   true,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			By("Creating a retained claim with the requested settings")
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			volumeLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-volume-%v", jobName), Namespace: jobNamespace}
+			createdVolume := &corev1.PersistentVolumeClaim{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, volumeLookupKey, createdVolume)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdVolume.Spec.Resources.Requests.Storage().String()).Should(Equal("2Gi"))
+			Expect(createdVolume.Spec.StorageClassName).Should(Equal(&storageClass.Name))
+			Expect(createdVolume.Spec.AccessModes).Should(Equal([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}))
+			Expect(createdVolume.OwnerReferences).Should(BeEmpty())
+
+			By("Expanding the bound claim when the size grows")
+			createdVolume.Status.Phase = corev1.ClaimBound
+			Expect(k8sClient.Status().Update(ctx, createdVolume)).Should(Succeed())
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				expandedSize := resource.MustParse("3Gi")
+				pwJob.Spec.Volume.Size = &expandedSize
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, volumeLookupKey, createdVolume); err != nil {
+					return ""
+				}
+				return createdVolume.Spec.Resources.Requests.Storage().String()
+			}, timeout, interval).Should(Equal("3Gi"))
+
+			By("Owning the claim once it is not retained anymore")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				pwJob.Spec.Volume.RetainOnDelete = false
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, volumeLookupKey, createdVolume); err != nil {
+					return false
+				}
+				return metav1.IsControlledBy(createdVolume, pwJob)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should mount an existing claim as state volume", func() {
+			ctx := context.Background()
+			jobName := "existing-volume"
+			existingVolume := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "existing-state",
+					Namespace: jobNamespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, existingVolume)).Should(Succeed())
+
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Tap:      defaultTapSpec,
+					Target:   defaultTargetSpec,
+					Volume: &batchv1alpha1.VolumeSpec{
+						ExistingClaimName: existingVolume.Name,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			createdCronJob := &batchv1.CronJob{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}, createdCronJob)
+			}, timeout, interval).Should(Succeed())
+			Expect(createdCronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes).Should(ContainElement(corev1.Volume{
+				Name: "runtime-volume",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: existingVolume.Name},
+				},
+			}))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("pw-volume-%v", jobName), Namespace: jobNamespace}, &corev1.PersistentVolumeClaim{}))).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: existingVolume.Name, Namespace: jobNamespace}, existingVolume)).Should(Succeed())
+			Expect(existingVolume.OwnerReferences).Should(BeEmpty())
+		})
+
+		It("Should move the configuration between ConfigMap and Secret storage", func() {
+			ctx := context.Background()
+			jobName := "config-storage"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule:      cron,
+					Tap:           defaultTapSpec,
+					Target:        defaultTargetSpec,
+					ConfigStorage: batchv1alpha1.ConfigMapConfigStorage,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			pwConfigLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-config-%v", jobName), Namespace: jobNamespace}
+			cronJobLookupKey := types.NamespacedName{Name: fmt.Sprintf("pw-job-%v", jobName), Namespace: jobNamespace}
+			configVolume := func() corev1.VolumeSource {
+				createdCronJob := &batchv1.CronJob{}
+				if err := k8sClient.Get(ctx, cronJobLookupKey, createdCronJob); err != nil {
+					return corev1.VolumeSource{}
+				}
+				for _, volume := range createdCronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes {
+					if volume.Name == "pipelinewise-configuration" {
+						return volume.VolumeSource
+					}
+				}
+				return corev1.VolumeSource{}
+			}
+
+			By("Rendering into a ConfigMap when requested")
+			Eventually(func() error {
+				return k8sClient.Get(ctx, pwConfigLookupKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() *corev1.ConfigMapVolumeSource {
+				return configVolume().ConfigMap
+			}, timeout, interval).ShouldNot(BeNil())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, pwConfigLookupKey, &corev1.Secret{}))).Should(BeTrue())
+
+			By("Moving the configuration into a Secret")
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return err
+				}
+				pwJob.Spec.ConfigStorage = batchv1alpha1.SecretConfigStorage
+				return k8sClient.Update(ctx, pwJob)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() *corev1.SecretVolumeSource {
+				return configVolume().Secret
+			}, timeout, interval).ShouldNot(BeNil())
+			Expect(k8sClient.Get(ctx, pwConfigLookupKey, &corev1.Secret{})).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, pwConfigLookupKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should report a degraded job when configuration can not be rendered", func() {
+			ctx := context.Background()
+			jobName := "status-degraded"
+			pwJob := &batchv1alpha1.PipelinewiseJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobName,
+					Namespace: jobNamespace,
+				},
+				Spec: batchv1alpha1.PipelinewiseJobSpec{
+					Schedule: cron,
+					Target:   defaultTargetSpec,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pwJob)).Should(Succeed())
+
+			pwJobLookupKey := types.NamespacedName{Name: jobName, Namespace: jobNamespace}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, pwJobLookupKey, pwJob); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionFalse(pwJob.Status.Conditions, batchv1alpha1.ConditionConfigRendered) &&
+					meta.IsStatusConditionTrue(pwJob.Status.Conditions, batchv1alpha1.ConditionDegraded)
+			}, timeout, interval).Should(BeTrue())
+			Expect(meta.FindStatusCondition(pwJob.Status.Conditions, batchv1alpha1.ConditionDegraded).Reason).Should(Equal("RenderFailed"))
+
+			By("Counting the failure in the config stage")
+			errorLabels := prometheus.Labels{"namespace": jobNamespace, "job": jobName, "tap_type": "", "target_type": batchv1alpha1.GetTargetConnectorID(pwJob), "stage": "config"}
+			Expect(testutil.ToFloat64(jobReconcileErrors.With(errorLabels))).Should(BeNumerically(">=", 1))
+
+			By("Emitting a warning event once")
+			Eventually(func() []string {
+				return eventReasons(ctx, pwJob, corev1.EventTypeWarning)
+			}, timeout, interval).Should(ContainElement("RenderFailed"))
+			Consistently(func() []string {
+				return eventReasons(ctx, pwJob, corev1.EventTypeWarning)
+			}, time.Second, interval).Should(HaveLen(1))
+		})
+	})
 })
+
+// eventReasons lists the reasons of the events emitted on the job, filtered by event type unless empty
+func eventReasons(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, eventType string) []string {
+	var events corev1.EventList
+	if err := k8sClient.List(ctx, &events, client.InNamespace(pwJob.Namespace)); err != nil {
+		return nil
+	}
+	reasons := []string{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.UID == pwJob.UID && (eventType == "" || event.Type == eventType) {
+			reasons = append(reasons, event.Reason)
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,87 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// PipelinewiseTapReconciler tracks the jobs referencing a PipelinewiseTap and blocks its deletion while any is left.
+// Referencing jobs re-render their configuration on their own
+type PipelinewiseTapReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile records the referencing jobs in status and keeps the in-use finalizer while there are any
+// +kubebuilder:rbac:groups=batch.pipelinewise,resources=pipelinewisetaps,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch.pipelinewise,resources=pipelinewisetaps/status,verbs=get;update;patch
+func (r *PipelinewiseTapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("pipelinewisetap", req.NamespacedName)
+
+	var tap batchv1alpha1.PipelinewiseTap
+	if err := r.Get(ctx, req.NamespacedName, &tap); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	referencedBy, err := listReferencingJobs(ctx, r.Client, tap.Namespace, func(pwJob *batchv1alpha1.PipelinewiseJob) bool {
+		return pwJob.ReferencesTap(tap.Name)
+	})
+	if err != nil {
+		log.Error(err, "Failed to list referencing jobs")
+		return ctrl.Result{}, err
+	}
+	if len(referencedBy) == 0 {
+		referencedBy = nil
+	}
+	if !equality.Semantic.DeepEqual(tap.Status.ReferencedBy, referencedBy) {
+		tap.Status.ReferencedBy = referencedBy
+		if err := r.Status().Update(ctx, &tap); err != nil {
+			log.Error(err, "Failed to update PipelinewiseTap status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !tap.DeletionTimestamp.IsZero() && len(referencedBy) > 0 {
+		log.Info("Deletion blocked while referenced", "jobs", referencedBy)
+	}
+	if err := syncInUseFinalizer(ctx, r.Client, &tap, len(referencedBy) > 0); err != nil {
+		log.Error(err, "Failed to update in-use finalizer")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager operator manager entrypoint
+func (r *PipelinewiseTapReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&batchv1alpha1.PipelinewiseTap{}).
+		Watches(&source.Kind{Type: &batchv1alpha1.PipelinewiseJob{}}, handler.EnqueueRequestsFromMapFunc(pipelinewiseJobToTap)).
+		Complete(r)
+}
@@ -0,0 +1,120 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// applyPodTemplate merges the pod template overrides of the job into the executor pod.
+// Shared container overrides are applied first, so container specific overrides win
+func applyPodTemplate(pod *corev1.PodTemplateSpec, podTemplate *batchv1alpha1.PodTemplateSpec) {
+	if podTemplate == nil {
+		return
+	}
+
+	pod.Labels = mergeStringMap(pod.Labels, podTemplate.Labels)
+	pod.Annotations = mergeStringMap(pod.Annotations, podTemplate.Annotations)
+	pod.Spec.NodeSelector = mergeStringMap(pod.Spec.NodeSelector, podTemplate.NodeSelector)
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, podTemplate.Tolerations...)
+	if podTemplate.Affinity != nil {
+		pod.Spec.Affinity = podTemplate.Affinity.DeepCopy()
+	}
+	if podTemplate.PriorityClassName != "" {
+		pod.Spec.PriorityClassName = podTemplate.PriorityClassName
+	}
+	if podTemplate.ServiceAccountName != "" {
+		pod.Spec.ServiceAccountName = podTemplate.ServiceAccountName
+	}
+	pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, podTemplate.ImagePullSecrets...)
+	if podTemplate.SecurityContext != nil {
+		pod.Spec.SecurityContext = podTemplate.SecurityContext.DeepCopy()
+	}
+
+	// Runners of jobs with several taps run as init containers, except for the last one
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for nth := range containers {
+			applyContainerOverride(&containers[nth], podTemplate.Container)
+			if isImportContainer(containers[nth].Name) {
+				applyContainerOverride(&containers[nth], podTemplate.Import)
+			}
+			if isRunnerContainer(containers[nth].Name) {
+				applyContainerOverride(&containers[nth], podTemplate.Runner)
+			}
+		}
+	}
+}
+
+// applyContainerOverride merges resources per resource name and env variables per name into the container
+func applyContainerOverride(container *corev1.Container, override *batchv1alpha1.ContainerOverrideSpec) {
+	if override == nil {
+		return
+	}
+
+	if override.Resources != nil {
+		container.Resources.Requests = mergeResourceList(container.Resources.Requests, override.Resources.Requests)
+		container.Resources.Limits = mergeResourceList(container.Resources.Limits, override.Resources.Limits)
+	}
+	if override.SecurityContext != nil {
+		container.SecurityContext = override.SecurityContext.DeepCopy()
+	}
+	for _, envVar := range override.Env {
+		replaced := false
+		for nth := range container.Env {
+			if container.Env[nth].Name == envVar.Name {
+				container.Env[nth] = *envVar.DeepCopy()
+				replaced = true
+			}
+		}
+		if !replaced {
+			container.Env = append(container.Env, *envVar.DeepCopy())
+		}
+	}
+	if override.ImagePullPolicy != "" {
+		container.ImagePullPolicy = override.ImagePullPolicy
+	}
+}
+
+func mergeStringMap(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := map[string]string{}
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+func mergeResourceList(base, override corev1.ResourceList) corev1.ResourceList {
+	if len(override) == 0 {
+		return base
+	}
+	merged := corev1.ResourceList{}
+	for name, quantity := range base {
+		merged[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range override {
+		merged[name] = quantity.DeepCopy()
+	}
+	return merged
+}
@@ -0,0 +1,150 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+// resyncRunType labels Jobs resyncing tables through the resync request
+const resyncRunType string = "resync"
+
+// registerResync records a new resync request as pending, the request is only started once no other run uses the state volume
+func registerResync(pwJob *batchv1alpha1.PipelinewiseJob) {
+	resync := pwJob.Spec.Resync
+	if resync == nil || (pwJob.Status.LastResync != nil && pwJob.Status.LastResync.Token == resync.Token) {
+		return
+	}
+	pwJob.Status.LastResync = &batchv1alpha1.ResyncStatus{
+		Token:  resync.Token,
+		Tables: resync.Tables,
+		Phase:  batchv1alpha1.OperationPending,
+	}
+}
+
+// isResyncPending reports whether a resync request waits to be started
+func isResyncPending(pwJob *batchv1alpha1.PipelinewiseJob) bool {
+	return pwJob.Status.LastResync != nil && pwJob.Status.LastResync.Phase == batchv1alpha1.OperationPending
+}
+
+// startResync creates the Job of the pending resync request and records it as running
+func (r *PipelinewiseJobReconciler) startResync(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob) (*batchv1.Job, error) {
+	resyncRun, err := getResyncRun(pwJob, executorJob, *pwJob.Status.LastResync)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctrl.SetControllerReference(pwJob, &resyncRun, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, &resyncRun); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	startTime := metav1.Now()
+	pwJob.Status.LastResync.Phase = batchv1alpha1.OperationRunning
+	pwJob.Status.LastResync.JobName = resyncRun.Name
+	pwJob.Status.LastResync.StartTime = &startTime
+	return &resyncRun, nil
+}
+
+// getResyncRun builds a Job running `pipelinewise sync_tables` with the mounts of the executor CronJob template.
+// Jobs with several taps resync every tap replicating one of the requested tables, with the tables of that tap
+func getResyncRun(pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob, resync batchv1alpha1.ResyncStatus) (batchv1.Job, error) {
+	resyncRun := getOneOffRun(pwJob, executorJob, resyncRunType, resyncRunType, resync.Token)
+
+	pipelines := batchv1alpha1.GetPipelines(pwJob)
+	podSpec := &resyncRun.Spec.Template.Spec
+	steps := []pipelineStep{}
+	for nth, step := range getPipelineSteps(*podSpec) {
+		if nth >= len(pipelines) {
+			break
+		}
+		pipeline := pipelines[nth]
+		tables := resync.Tables
+		if len(pipelines) > 1 && len(resync.Tables) > 0 {
+			tables = pipelineTables(pipeline, resync.Tables)
+			if len(tables) == 0 {
+				continue
+			}
+		}
+
+		resyncArgs := []string{
+			"sync_tables",
+			"--tap",
+			string(pipeline.TapID()),
+			"--target",
+			string(pipeline.TargetID()),
+		}
+		if len(tables) > 0 {
+			resyncArgs = append(resyncArgs, "--tables", strings.Join(tables, ","))
+		}
+		step.runner.Args = resyncArgs
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return batchv1.Job{}, fmt.Errorf("None of the tables %v is replicated by a tap of the job", strings.Join(resync.Tables, ", "))
+	}
+	setPipelineSteps(podSpec, steps)
+	return resyncRun, nil
+}
+
+// pipelineTables returns the requested tables replicated by the pipeline
+func pipelineTables(pipeline batchv1alpha1.Pipeline, tables []string) []string {
+	replicated := map[string]bool{}
+	for _, table := range pipeline.Tables() {
+		replicated[table] = true
+	}
+	selected := []string{}
+	for _, table := range tables {
+		if replicated[table] {
+			selected = append(selected, table)
+		}
+	}
+	return selected
+}
+
+// updateResyncStatus records the outcome of a running resync Job
+func updateResyncStatus(pwJob *batchv1alpha1.PipelinewiseJob, jobs []batchv1.Job) {
+	resync := pwJob.Status.LastResync
+	if resync == nil || resync.Phase != batchv1alpha1.OperationRunning {
+		return
+	}
+
+	for i := range jobs {
+		if jobs[i].Name != resync.JobName {
+			continue
+		}
+		finishedType, finishedTime := getJobFinishedStatus(&jobs[i])
+		switch finishedType {
+		case batchv1.JobComplete:
+			resync.Phase = batchv1alpha1.OperationSucceeded
+			resync.CompletionTime = finishedTime
+		case batchv1.JobFailed:
+			resync.Phase = batchv1alpha1.OperationFailed
+			resync.CompletionTime = finishedTime
+		}
+	}
+}
@@ -0,0 +1,181 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// stateEditRunType labels Jobs changing the replication state through the state edit request
+	stateEditRunType string = "state-edit"
+	// stateEditEnv passes the state edit request to the state editor
+	stateEditEnv string = "PIPELINEWISE_STATE_EDIT"
+	// stateEditorScript applies the state edit request to the state file given as first argument.
+	// The previous state file is copied to the backup path given as second argument, a retried Job keeps the first backup
+	stateEditorScript string = `import json, os, shutil, sys
+
+state_path, backup_path = sys.argv[1], sys.argv[2]
+edit = json.loads(os.environ["` + stateEditEnv + `"])
+
+state = {}
+if os.path.exists(state_path):
+    if not os.path.exists(backup_path):
+        shutil.copy2(state_path, backup_path)
+    if not edit.get("reset"):
+        with open(state_path) as state_file:
+            state = json.load(state_file) or {}
+
+bookmarks = state.setdefault("bookmarks", {})
+for stream in edit.get("streams") or []:
+    if stream.get("bookmark"):
+        bookmarks[stream["stream"]] = stream["bookmark"]
+    else:
+        bookmarks.pop(stream["stream"], None)
+    if state.get("currently_syncing") == stream["stream"]:
+        state["currently_syncing"] = None
+
+os.makedirs(os.path.dirname(state_path), exist_ok=True)
+with open(state_path + ".tmp", "w") as state_file:
+    json.dump(state, state_file)
+os.replace(state_path + ".tmp", state_path)
+print(json.dumps(state))
+`
+)
+
+// registerStateEdit records a new state edit request as pending, the request is only started once no other run uses the state volume
+func registerStateEdit(pwJob *batchv1alpha1.PipelinewiseJob) {
+	stateEdit := pwJob.Spec.StateEdit
+	if stateEdit == nil || (pwJob.Status.LastStateEdit != nil && pwJob.Status.LastStateEdit.Token == stateEdit.Token) {
+		return
+	}
+	pwJob.Status.LastStateEdit = &batchv1alpha1.StateEditStatus{
+		Token: stateEdit.Token,
+		Phase: batchv1alpha1.OperationPending,
+	}
+}
+
+// isStateEditPending reports whether a state edit request waits to be started
+func isStateEditPending(pwJob *batchv1alpha1.PipelinewiseJob) bool {
+	return pwJob.Status.LastStateEdit != nil && pwJob.Status.LastStateEdit.Phase == batchv1alpha1.OperationPending
+}
+
+// getStateFilePath returns the location of the replication state written by `pipelinewise run_tap`
+func getStateFilePath(pipeline batchv1alpha1.Pipeline) string {
+	return fmt.Sprintf("/root/.pipelinewise/%v/%v/state.json", pipeline.TargetID(), pipeline.TapID())
+}
+
+// getStatePipeline returns the position of the pipeline whose state is edited, jobs with several taps or targets select it by tap id
+func getStatePipeline(pwJob *batchv1alpha1.PipelinewiseJob) (int, error) {
+	pipelines := batchv1alpha1.GetPipelines(pwJob)
+	if len(pipelines) == 1 {
+		return 0, nil
+	}
+	for nth, pipeline := range pipelines {
+		if string(pipeline.TapID()) == pwJob.Spec.StateEdit.Tap {
+			return nth, nil
+		}
+	}
+	return 0, fmt.Errorf("Tap %q of the state edit is not a tap of the job", pwJob.Spec.StateEdit.Tap)
+}
+
+// startStateEdit creates the Job of the pending state edit request and records it as running
+func (r *PipelinewiseJobReconciler) startStateEdit(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob) (*batchv1.Job, error) {
+	stateEditRun, backupPath, err := getStateEditRun(pwJob, executorJob)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctrl.SetControllerReference(pwJob, &stateEditRun, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, &stateEditRun); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	startTime := metav1.Now()
+	pwJob.Status.LastStateEdit.Phase = batchv1alpha1.OperationRunning
+	pwJob.Status.LastStateEdit.JobName = stateEditRun.Name
+	pwJob.Status.LastStateEdit.BackupPath = backupPath
+	pwJob.Status.LastStateEdit.StartTime = &startTime
+	return &stateEditRun, nil
+}
+
+// getStateEditRun builds a Job applying the state edit request with the runner image and mounts of the executor CronJob template.
+// The configuration is not imported, the Job only touches the state file of the selected tap. It returns the backup path of the previous state file
+func getStateEditRun(pwJob *batchv1alpha1.PipelinewiseJob, executorJob batchv1.CronJob) (batchv1.Job, string, error) {
+	stateEditRun := getOneOffRun(pwJob, executorJob, "state", stateEditRunType, pwJob.Spec.StateEdit.Token)
+
+	stateEdit, err := json.Marshal(pwJob.Spec.StateEdit)
+	if err != nil {
+		return batchv1.Job{}, "", err
+	}
+	pipelineNth, err := getStatePipeline(pwJob)
+	if err != nil {
+		return batchv1.Job{}, "", err
+	}
+	statePath := getStateFilePath(batchv1alpha1.GetPipelines(pwJob)[pipelineNth])
+	backupPath := fmt.Sprintf("%v.%v.bak", statePath, stateEditRun.Name)
+
+	podSpec := &stateEditRun.Spec.Template.Spec
+	steps := getPipelineSteps(*podSpec)
+	if pipelineNth >= len(steps) {
+		return batchv1.Job{}, "", fmt.Errorf("Executor pod does not run tap %q", pwJob.Spec.StateEdit.Tap)
+	}
+	runner := steps[pipelineNth].runner
+	runner.Command = []string{"python3", "-c", stateEditorScript}
+	runner.Args = []string{statePath, backupPath}
+	runner.Env = append(runner.Env, corev1.EnvVar{
+		Name:  stateEditEnv,
+		Value: string(stateEdit),
+	})
+	podSpec.InitContainers = nil
+	podSpec.Containers = []corev1.Container{runner}
+	return stateEditRun, backupPath, nil
+}
+
+// updateStateEditStatus records the outcome of a running state edit Job
+func updateStateEditStatus(pwJob *batchv1alpha1.PipelinewiseJob, jobs []batchv1.Job) {
+	stateEdit := pwJob.Status.LastStateEdit
+	if stateEdit == nil || stateEdit.Phase != batchv1alpha1.OperationRunning {
+		return
+	}
+
+	for i := range jobs {
+		if jobs[i].Name != stateEdit.JobName {
+			continue
+		}
+		finishedType, finishedTime := getJobFinishedStatus(&jobs[i])
+		switch finishedType {
+		case batchv1.JobComplete:
+			stateEdit.Phase = batchv1alpha1.OperationSucceeded
+			stateEdit.CompletionTime = finishedTime
+		case batchv1.JobFailed:
+			stateEdit.Phase = batchv1alpha1.OperationFailed
+			stateEdit.CompletionTime = finishedTime
+		}
+	}
+}
@@ -0,0 +1,277 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// scheduledRunType is reported for runs started by the executor CronJob, which carry no run type label
+	scheduledRunType string = "scheduled"
+	// jobNameLabel is set by Kubernetes on every pod of a Job
+	jobNameLabel string = "job-name"
+	// runnerScript runs pipelinewise and summarizes the rows per table into the termination message of the runner container.
+	// Extracted rows are counted from the singer `record_count` metrics of the tap, loaded rows from the `Loading into` lines
+	// of the target, both copied to the output by `--extra_log`. Failed runs add the tail of the output.
+	// The termination message is limited to 4096 bytes
+	runnerScript string = `set -o pipefail
+/app/entrypoint.sh "$@" 2>&1 | tee /tmp/pipelinewise-run.log
+exit_code=$?
+python3 - /tmp/pipelinewise-run.log $exit_code > /dev/termination-log <<'EOF' || true
+import collections, json, re, sys
+
+metric = re.compile(r"METRIC: (\{.*\})")
+loading = re.compile(r"Loading into (\S+): (\{.*\})")
+tables = {}
+tail = collections.deque(maxlen=50)
+
+def table(name):
+    name = name.replace('"', "").split(".")[-1].lower()
+    return tables.setdefault(name, {"name": name, "rowsExtracted": 0, "rowsLoaded": 0})
+
+with open(sys.argv[1], errors="replace") as log:
+    for line in log:
+        tail.append(line.rstrip("\n"))
+        try:
+            match = metric.search(line)
+            if match:
+                point = json.loads(match.group(1))
+                stream = (point.get("tags") or {}).get("endpoint")
+                if point.get("metric") == "record_count" and stream:
+                    table(stream)["rowsExtracted"] += int(point.get("value") or 0)
+                continue
+            match = loading.search(line)
+            if match:
+                stats = json.loads(match.group(2))
+                table(match.group(1))["rowsLoaded"] += int(stats.get("inserts") or 0) + int(stats.get("updates") or 0)
+        except (ValueError, AttributeError):
+            continue
+
+summary = {"tables": sorted(tables.values(), key=lambda t: t["name"])}
+if sys.argv[2] != "0":
+    summary["logTail"] = "\n".join(tail)
+
+def encode():
+    return json.dumps(summary, separators=(",", ":")).encode()
+
+message = encode()
+while len(message) > 4096 and tail:
+    tail.popleft()
+    summary["logTail"] = "\n".join(tail)
+    message = encode()
+while len(message) > 4096 and summary["tables"]:
+    summary["tables"].pop()
+    message = encode()
+sys.stdout.buffer.write(message)
+EOF
+exit $exit_code`
+)
+
+// runSummary is the termination message written by the runner script
+type runSummary struct {
+	Tables  []batchv1alpha1.TableStatistics `json:"tables"`
+	LogTail string                          `json:"logTail,omitempty"`
+}
+
+// finishedRun is a run recorded in status during the current reconciliation
+type finishedRun struct {
+	batchv1alpha1.RunStatistics
+	// LogTail holds the last output lines of the failed container
+	LogTail string
+	// TapID is the tap run by a Job of a tap CronJob, empty when the run covers every tap of the job
+	TapID string
+	// TargetID is the target loaded by a Job of a tap CronJob, empty when the run covers every target of the job
+	TargetID string
+}
+
+// terminatedRunner is the terminated state of a runner container
+type terminatedRunner struct {
+	*corev1.ContainerStateTerminated
+	name string
+}
+
+// runTermination collects the terminated containers of the latest pod of a Job
+type runTermination struct {
+	// runners are the terminated runner containers in run order, empty when none ran
+	runners []terminatedRunner
+	// failedContainer is the name of the first container which exited with an error
+	failedContainer string
+	// failedMessage is the termination message of the failed container
+	failedMessage string
+}
+
+// updateRunStatistics records finished runs in status together with the statistics reported by their runner container
+// and returns the runs recorded for the first time, oldest first. Only the most recent runs are kept, runs dropped from
+// a full history are not recorded again
+func (r *PipelinewiseJobReconciler) updateRunStatistics(ctx context.Context, pwJob *batchv1alpha1.PipelinewiseJob, jobs []batchv1.Job) ([]finishedRun, error) {
+	limit := int(batchv1alpha1.GetRunHistoryLimit(pwJob))
+	runs := append([]batchv1alpha1.RunStatistics{}, pwJob.Status.RecentRuns...)
+	newRuns := []finishedRun{}
+	recorded := map[string]bool{}
+	for _, run := range runs {
+		recorded[run.JobName] = true
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if recorded[job.Name] || job.Labels[runTypeLabel] == stateEditRunType {
+			continue
+		}
+		finishedType, finishedTime := getJobFinishedStatus(job)
+		if finishedType == "" {
+			continue
+		}
+		if len(pwJob.Status.RecentRuns) >= limit && (limit == 0 || !pwJob.Status.RecentRuns[limit-1].CompletionTime.Before(finishedTime)) {
+			continue
+		}
+
+		run := batchv1alpha1.RunStatistics{
+			JobName:             job.Name,
+			RunType:             job.Labels[runTypeLabel],
+			Succeeded:           finishedType == batchv1.JobComplete,
+			StartTime:           job.Status.StartTime,
+			CompletionTime:      finishedTime,
+			NotificationPending: true,
+		}
+		if run.RunType == "" {
+			run.RunType = scheduledRunType
+		}
+		if job.Status.StartTime != nil {
+			run.DurationSeconds = int64(finishedTime.Sub(job.Status.StartTime.Time).Seconds())
+		}
+
+		termination, err := r.getRunTermination(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		// Runners stop at the first failure, so the last runner reports the exit code of the run
+		for _, runner := range termination.runners {
+			exitCode := runner.ExitCode
+			run.ExitCode = &exitCode
+			var summary runSummary
+			if err := json.Unmarshal([]byte(runner.Message), &summary); err != nil {
+				r.Log.Info("Ignoring unreadable run summary", "job", job.Name, "error", err.Error())
+			}
+			// Tables are attributed to the pipeline of the runner, so several pipelines replicating a table keep apart
+			tap, target := getRunnerPipeline(job, runner.name)
+			for _, table := range summary.Tables {
+				table.Tap, table.Target = tap, target
+				run.Tables = append(run.Tables, table)
+			}
+		}
+		run.FailedContainer = termination.failedContainer
+		runs = append(runs, run)
+		newRuns = append(newRuns, getFinishedRun(job, run, termination))
+	}
+	sort.SliceStable(newRuns, func(i, j int) bool {
+		return newRuns[i].CompletionTime.Before(newRuns[j].CompletionTime)
+	})
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].CompletionTime.Equal(runs[j].CompletionTime) {
+			return runs[i].JobName > runs[j].JobName
+		}
+		return runs[j].CompletionTime.Before(runs[i].CompletionTime)
+	})
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	if len(runs) == 0 {
+		runs = nil
+	}
+	pwJob.Status.RecentRuns = runs
+	return newRuns, nil
+}
+
+// getRunnerPipeline returns the tap and target id the runner container of the Job runs, read from its arguments
+func getRunnerPipeline(job *batchv1.Job, containerName string) (string, string) {
+	podSpec := job.Spec.Template.Spec
+	tap, target := "", ""
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		if container.Name != containerName {
+			continue
+		}
+		for nth := 0; nth < len(container.Args)-1; nth++ {
+			switch container.Args[nth] {
+			case "--tap":
+				tap = container.Args[nth+1]
+			case "--target":
+				target = container.Args[nth+1]
+			}
+		}
+	}
+	return tap, target
+}
+
+// getFinishedRun adds the details of the Job needed to notify about the run to its statistics
+func getFinishedRun(job *batchv1.Job, run batchv1alpha1.RunStatistics, termination runTermination) finishedRun {
+	// Runners stop at the first failure, so the last runner reports the output of a failed run
+	logTail := termination.failedMessage
+	if isRunnerContainer(termination.failedContainer) && len(termination.runners) > 0 {
+		var summary runSummary
+		_ = json.Unmarshal([]byte(termination.runners[len(termination.runners)-1].Message), &summary)
+		logTail = summary.LogTail
+	}
+	return finishedRun{
+		RunStatistics: run,
+		LogTail:       logTail,
+		TapID:         job.Annotations[tapIDAnnotation],
+		TargetID:      job.Annotations[targetIDAnnotation],
+	}
+}
+
+// getRunTermination returns the terminated containers of the latest pod of the Job, which is empty when the pods of the Job
+// are already removed
+func (r *PipelinewiseJobReconciler) getRunTermination(ctx context.Context, job *batchv1.Job) (runTermination, error) {
+	// Pods of runs are not labeled with the job name, so they are listed from the API server
+	var pods corev1.PodList
+	if err := r.apiReader().List(ctx, &pods, client.InNamespace(job.Namespace), client.MatchingLabels{jobNameLabel: job.Name}); err != nil {
+		return runTermination{}, err
+	}
+	if len(pods.Items) == 0 {
+		return runTermination{}, nil
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[j].CreationTimestamp.Before(&pods.Items[i].CreationTimestamp)
+	})
+
+	termination := runTermination{}
+	pod := pods.Items[0]
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			continue
+		}
+		if isRunnerContainer(status.Name) {
+			termination.runners = append(termination.runners, terminatedRunner{ContainerStateTerminated: terminated, name: status.Name})
+		}
+		if terminated.ExitCode != 0 && termination.failedContainer == "" {
+			termination.failedContainer = status.Name
+			termination.failedMessage = terminated.Message
+		}
+	}
+	return termination, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"os"
 	"path"
 	"path/filepath"
@@ -43,6 +44,7 @@ import (
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var cancel context.CancelFunc
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -78,19 +80,43 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 	Expect(k8sClient).ToNot(BeNil())
 
+	// Namespaces other than default are left to reconcilers started by the tests themselves
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme: scheme.Scheme,
+		Scheme:    scheme.Scheme,
+		Namespace: "default",
+		NewCache:  NewCache(),
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	// The job reconciler reads through the cache of the manager like in the operator, so objects left out of the cache
+	// must be read through the API reader
 	err = (&PipelinewiseJobReconciler{
+		Client:    k8sManager.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("PipelinewiseJob"),
+		Scheme:    k8sManager.GetScheme(),
+		Recorder:  k8sManager.GetEventRecorderFor("pipelinewisejob-controller"),
+		APIReader: k8sManager.GetAPIReader(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&PipelinewiseTapReconciler{
+		Client: k8sClient,
+		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseTap"),
+		Scheme: k8sManager.GetScheme(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&PipelinewiseTargetReconciler{
 		Client: k8sClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseJob"),
+		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseTarget"),
+		Scheme: k8sManager.GetScheme(),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(ctrl.SetupSignalHandler())
 	go func() {
-		err = k8sManager.Start(ctrl.SetupSignalHandler())
+		err = k8sManager.Start(ctx)
 		Expect(err).ToNot(HaveOccurred())
 	}()
 
@@ -102,6 +128,8 @@ var _ = BeforeSuite(func(done Done) {
 
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	// Stop the manager first, the API server does not shut down while its watches are open
+	cancel()
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
@@ -0,0 +1,86 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	batchv1alpha1 "github.com/dirathea/pipelinewise-operator/api/v1alpha1"
+)
+
+const (
+	// vaultHeader is the first line of ansible-vault 1.1 AES256 payloads
+	vaultHeader string = "$ANSIBLE_VAULT;1.1;AES256"
+	// vaultKDFIterations is the PBKDF2 iteration count used by ansible-vault
+	vaultKDFIterations int = 10000
+	// vaultLineWidth is the width ansible-vault wraps the hex payload at
+	vaultLineWidth int = 80
+)
+
+// vaultEncrypter encrypts sensitive values with the pipelinewise master password, the same way
+// `pipelinewise encrypt_string` does. The salt is derived from the master password, the field path and the value,
+// so unchanged values render to the same ciphertext and the configuration is not rewritten on every reconciliation.
+func vaultEncrypter(prefix string, masterPassword []byte) batchv1alpha1.SensitiveValueEncrypter {
+	// Pipelinewise reads the master password from a file and strips surrounding whitespace
+	password := bytes.TrimSpace(masterPassword)
+	return func(fieldPath []string, value string) (string, error) {
+		saltMac := hmac.New(sha256.New, password)
+		saltMac.Write([]byte(strings.Join(append([]string{prefix}, fieldPath...), ".")))
+		saltMac.Write([]byte{0})
+		saltMac.Write([]byte(value))
+		return vaultEncrypt(password, saltMac.Sum(nil), []byte(value)), nil
+	}
+}
+
+// vaultEncrypt returns the ansible-vault 1.1 AES256 payload of the plaintext, including the header
+func vaultEncrypt(password, salt, plaintext []byte) string {
+	derivedKey := pbkdf2.Key(password, salt, vaultKDFIterations, 2*32+aes.BlockSize, sha256.New)
+	cipherKey, hmacKey, iv := derivedKey[:32], derivedKey[32:64], derivedKey[64:]
+
+	// ansible-vault pads the plaintext with PKCS#7 even though CTR mode does not need it
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, _ := aes.NewCipher(cipherKey)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, padded)
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+
+	payload := strings.Join([]string{
+		hex.EncodeToString(salt),
+		hex.EncodeToString(mac.Sum(nil)),
+		hex.EncodeToString(ciphertext),
+	}, "\n")
+	encoded := hex.EncodeToString([]byte(payload))
+
+	lines := []string{vaultHeader}
+	for len(encoded) > vaultLineWidth {
+		lines = append(lines, encoded[:vaultLineWidth])
+		encoded = encoded[vaultLineWidth:]
+	}
+	return strings.Join(append(lines, encoded), "\n")
+}
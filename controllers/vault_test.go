@@ -0,0 +1,48 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vault encryption", func() {
+	It("Should produce ansible-vault 1.1 AES256 payloads", func() {
+		// Decrypts with ansible-vault using the password "secret"
+		expected := "$ANSIBLE_VAULT;1.1;AES256\n" +
+			"33303331333233333334333533363337333833393631363236333634363536363330333133323333\n" +
+			"3334333533363337333833393631363236333634363536360a616135613733313565653237313961\n" +
+			"30626632383661386331383634306534643535323734336337353237326165616232663133656566\n" +
+			"6133383736373565350a306237646635333334323266336131313537663038653431366433663536\n" +
+			"32396563386630333734396233333064393232343864343839376536386265343164393962316230\n" +
+			"6235393539656435346636363563656633383763613533386463"
+		Expect(vaultEncrypt([]byte("secret"), []byte("0123456789abcdef0123456789abcdef"), []byte("hello world, a value longer than 16"))).Should(Equal(expected))
+	})
+
+	It("Should render stable encrypted strings per field and value", func() {
+		encrypt := vaultEncrypter("target", []byte("secret\n"))
+		encrypted, err := encrypt([]string{"password"}, "value")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(encrypted).Should(HavePrefix("$ANSIBLE_VAULT;1.1;AES256\n"))
+
+		Expect(encrypt([]string{"password"}, "value")).Should(Equal(encrypted))
+		Expect(vaultEncrypter("target", []byte("secret"))([]string{"password"}, "value")).Should(Equal(encrypted))
+		Expect(encrypt([]string{"aws_secret_access_key"}, "value")).ShouldNot(Equal(encrypted))
+		Expect(encrypt([]string{"password"}, "other value")).ShouldNot(Equal(encrypted))
+	})
+})
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -58,26 +59,80 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	if err := controllers.ValidateMainConfigSetting(); err != nil {
+		setupLog.Error(err, "invalid main configuration setting")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
 		Port:               9443,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "8c999310.pipelinewise",
+		NewCache:           controllers.NewCache(),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// batch/v1 CronJobs are served since Kubernetes 1.21, older clusters only serve batch/v1beta1
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	cronJobVersion, err := controllers.DetectCronJobVersion(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to detect served CronJob version")
+		os.Exit(1)
+	}
+	setupLog.Info("managing executor CronJobs", "version", "batch/"+string(cronJobVersion))
+
 	if err = (&controllers.PipelinewiseJobReconciler{
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("PipelinewiseJob"),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorderFor("pipelinewisejob-controller"),
+		CronJobVersion: cronJobVersion,
+		APIReader:      mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PipelinewiseJob")
+		os.Exit(1)
+	}
+	if err = (&controllers.PipelinewiseTapReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseJob"),
+		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseTap"),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PipelinewiseJob")
+		setupLog.Error(err, "unable to create controller", "controller", "PipelinewiseTap")
+		os.Exit(1)
+	}
+	if err = (&controllers.PipelinewiseTargetReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("PipelinewiseTarget"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PipelinewiseTarget")
 		os.Exit(1)
 	}
+	// Webhooks need serving certificates, deployments providing them set ENABLE_WEBHOOKS=true
+	viper.SetDefault("ENABLE_WEBHOOKS", false)
+	if viper.GetBool("ENABLE_WEBHOOKS") {
+		if err = (&batchv1alpha1.PipelinewiseJob{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PipelinewiseJob")
+			os.Exit(1)
+		}
+		if err = (&batchv1alpha1.PipelinewiseTap{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PipelinewiseTap")
+			os.Exit(1)
+		}
+		if err = (&batchv1alpha1.PipelinewiseTarget{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PipelinewiseTarget")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")